@@ -25,8 +25,14 @@
 package assert
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
 // TB is a tiny subset of [testing.TB] used by [assert].
@@ -59,13 +65,99 @@ func (F) method(t TB) func(format string, args ...any) { return t.Fatalf }
 // Equal asserts that got and want are equal. Optional formatAndArgs can be
 // provided to customize the error message, the first element must be a string,
 // otherwise Equal panics.
+//
+// If got and want are structs, maps, slices, or pointers to them, the error
+// message lists only the differing parts, e.g. a single struct field, which
+// keeps the output readable for large values:
+//
+//	values of type config.Config differ:
+//		.DB.Port: got 5432; want 5433
 func Equal[T Parameter, V any](t TB, got, want V, formatAndArgs ...any) {
 	t.Helper()
-	if !reflect.DeepEqual(got, want) {
+	if reflect.DeepEqual(got, want) {
+		return
+	}
+
+	gv, wv := reflect.ValueOf(got), reflect.ValueOf(want)
+	if gv.IsValid() && wv.IsValid() && gv.Type() == wv.Type() && isComposite(gv.Type()) {
+		if lines := diffValues("", gv, wv); len(lines) > 0 {
+			fail[T](t, formatAndArgs, "values of type %T differ:\n\t%s", got, strings.Join(lines, "\n\t"))
+			return
+		}
+	}
+	fail[T](t, formatAndArgs, "got %v; want %v", got, want)
+}
+
+// Ordered is a constraint that permits any ordered type, i.e. any type that
+// supports the < operator.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Number is a constraint that permits any integer or floating-point type,
+// including the types derived from them, e.g. [time.Duration].
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Greater asserts that got is greater than bound. Optional formatAndArgs can
+// be provided to customize the error message, the first element must be a
+// string, otherwise Greater panics.
+func Greater[T Parameter, V Ordered](t TB, got, bound V, formatAndArgs ...any) {
+	t.Helper()
+	if !(got > bound) {
+		fail[T](t, formatAndArgs, "got %v; want > %v", got, bound)
+	}
+}
+
+// Less asserts that got is less than bound. Optional formatAndArgs can be
+// provided to customize the error message, the first element must be a string,
+// otherwise Less panics.
+func Less[T Parameter, V Ordered](t TB, got, bound V, formatAndArgs ...any) {
+	t.Helper()
+	if !(got < bound) {
+		fail[T](t, formatAndArgs, "got %v; want < %v", got, bound)
+	}
+}
+
+// InDelta asserts that got is within delta of want, inclusive, e.g. that a
+// measured duration is close enough to the expected one. Optional
+// formatAndArgs can be provided to customize the error message, the first
+// element must be a string, otherwise InDelta panics.
+func InDelta[T Parameter, V Number](t TB, got, want, delta V, formatAndArgs ...any) {
+	t.Helper()
+	// convert to float64 to avoid overflows and unsigned wraparounds.
+	if diff := math.Abs(float64(got) - float64(want)); math.IsNaN(diff) || diff > float64(delta) {
+		fail[T](t, formatAndArgs, "got %v; want %v ± %v", got, want, delta)
+	}
+}
+
+// EqualFunc asserts that got and want are equal, using the provided eq
+// function instead of [reflect.DeepEqual]. It is useful for the types that
+// cannot be compared deeply, e.g. [time.Time] (use [time.Time.Equal]).
+// Optional formatAndArgs can be provided to customize the error message, the
+// first element must be a string, otherwise EqualFunc panics.
+func EqualFunc[T Parameter, V any](t TB, got, want V, eq func(a, b V) bool, formatAndArgs ...any) {
+	t.Helper()
+	if !eq(got, want) {
 		fail[T](t, formatAndArgs, "got %v; want %v", got, want)
 	}
 }
 
+// NotEqual asserts that got and want are not equal, using [reflect.DeepEqual].
+// Optional formatAndArgs can be provided to customize the error message, the
+// first element must be a string, otherwise NotEqual panics.
+func NotEqual[T Parameter, V any](t TB, got, want V, formatAndArgs ...any) {
+	t.Helper()
+	if reflect.DeepEqual(got, want) {
+		fail[T](t, formatAndArgs, "got %v; want a different value", got)
+	}
+}
+
 // NoErr asserts that err is nil. Optional formatAndArgs can be provided to
 // customize the error message, the first element must be a string, otherwise
 // NoErr panics.
@@ -96,6 +188,430 @@ func AsErr[T Parameter](t TB, err error, target any, formatAndArgs ...any) {
 	}
 }
 
+// ErrorContains asserts that err is not nil and its message contains substr.
+// Optional formatAndArgs can be provided to customize the error message, the
+// first element must be a string, otherwise ErrorContains panics.
+func ErrorContains[T Parameter](t TB, err error, substr string, formatAndArgs ...any) {
+	t.Helper()
+	switch {
+	case err == nil:
+		fail[T](t, formatAndArgs, "got no error; want error containing %q", substr)
+	case !strings.Contains(err.Error(), substr):
+		fail[T](t, formatAndArgs, "error %q does not contain %q", err, substr)
+	}
+}
+
+// True asserts that cond is true. Optional formatAndArgs can be provided to
+// customize the error message, the first element must be a string, otherwise
+// True panics.
+func True[T Parameter](t TB, cond bool, formatAndArgs ...any) {
+	t.Helper()
+	if !cond {
+		fail[T](t, formatAndArgs, "condition is false; want true")
+	}
+}
+
+// False asserts that cond is false. Optional formatAndArgs can be provided to
+// customize the error message, the first element must be a string, otherwise
+// False panics.
+func False[T Parameter](t TB, cond bool, formatAndArgs ...any) {
+	t.Helper()
+	if cond {
+		fail[T](t, formatAndArgs, "condition is true; want false")
+	}
+}
+
+// Nil asserts that v is nil. Unlike comparing v with nil directly, it also
+// handles typed nils, e.g. a nil pointer stored in an interface. Optional
+// formatAndArgs can be provided to customize the error message, the first
+// element must be a string, otherwise Nil panics.
+func Nil[T Parameter](t TB, v any, formatAndArgs ...any) {
+	t.Helper()
+	if !isNil(v) {
+		fail[T](t, formatAndArgs, "got %v; want nil", v)
+	}
+}
+
+// NotNil asserts that v is not nil. Unlike comparing v with nil directly, it
+// also handles typed nils, e.g. a nil pointer stored in an interface. Optional
+// formatAndArgs can be provided to customize the error message, the first
+// element must be a string, otherwise NotNil panics.
+func NotNil[T Parameter](t TB, v any, formatAndArgs ...any) {
+	t.Helper()
+	if isNil(v) {
+		fail[T](t, formatAndArgs, "got nil (%T); want non-nil", v)
+	}
+}
+
+// JSONEqual asserts that got and want are semantically equal JSON documents,
+// i.e. ignoring the order of object keys and insignificant whitespace.
+// Optional formatAndArgs can be provided to customize the error message, the
+// first element must be a string, otherwise JSONEqual panics.
+func JSONEqual[T Parameter](t TB, got, want string, formatAndArgs ...any) {
+	t.Helper()
+	var gv, wv any
+	if err := json.Unmarshal([]byte(got), &gv); err != nil {
+		fail[T](t, formatAndArgs, "got invalid JSON: %v", err)
+		return
+	}
+	if err := json.Unmarshal([]byte(want), &wv); err != nil {
+		fail[T](t, formatAndArgs, "want invalid JSON: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(gv, wv) {
+		// re-encode both documents so that they are compact and their keys
+		// are sorted, which makes them easier to compare.
+		gb, _ := json.Marshal(gv)
+		wb, _ := json.Marshal(wv)
+		fail[T](t, formatAndArgs, "got %s; want %s", gb, wb)
+	}
+}
+
+// ElementsMatch asserts that got and want contain the same elements, compared
+// using [reflect.DeepEqual], regardless of their order. The number of
+// occurrences of each element must match as well. Optional formatAndArgs can
+// be provided to customize the error message, the first element must be a
+// string, otherwise ElementsMatch panics.
+func ElementsMatch[T Parameter, V any](t TB, got, want []V, formatAndArgs ...any) {
+	t.Helper()
+	missing, extra := diffElements(got, want)
+	if len(missing) == 0 && len(extra) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("got %v; want %v in any order", got, want)
+	if len(missing) > 0 {
+		msg += fmt.Sprintf("\nmissing: %v", missing)
+	}
+	if len(extra) > 0 {
+		msg += fmt.Sprintf("\nextra: %v", extra)
+	}
+	fail[T](t, formatAndArgs, "%s", msg)
+}
+
+// Subset asserts that every element of subset is present in list, compared
+// using [reflect.DeepEqual]. Optional formatAndArgs can be provided to
+// customize the error message, the first element must be a string, otherwise
+// Subset panics.
+func Subset[T Parameter, V any](t TB, list, subset []V, formatAndArgs ...any) {
+	t.Helper()
+	var missing []V
+	for _, elem := range subset {
+		if indexOf(list, elem, nil) < 0 {
+			missing = append(missing, elem)
+		}
+	}
+	if len(missing) > 0 {
+		fail[T](t, formatAndArgs, "%v does not contain %v", list, missing)
+	}
+}
+
+// diffElements returns the elements of want missing from got and the elements
+// of got not present in want, respecting the number of occurrences.
+func diffElements[V any](got, want []V) (missing, extra []V) {
+	used := make([]bool, len(got))
+	for _, elem := range want {
+		i := indexOf(got, elem, used)
+		if i < 0 {
+			missing = append(missing, elem)
+			continue
+		}
+		used[i] = true
+	}
+	for i, elem := range got {
+		if !used[i] {
+			extra = append(extra, elem)
+		}
+	}
+	return missing, extra
+}
+
+// indexOf returns the index of the first element of list equal to elem and not
+// marked as used, or -1, if there is no such element. used may be nil.
+func indexOf[V any](list []V, elem V, used []bool) int {
+	for i := range list {
+		if used != nil && used[i] {
+			continue
+		}
+		if reflect.DeepEqual(list[i], elem) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Zero asserts that v is the zero value of its type, as reported by
+// [reflect.Value.IsZero]. Optional formatAndArgs can be provided to customize
+// the error message, the first element must be a string, otherwise Zero panics.
+func Zero[T Parameter](t TB, v any, formatAndArgs ...any) {
+	t.Helper()
+	if !isZero(v) {
+		fail[T](t, formatAndArgs, "got %v; want zero value", v)
+	}
+}
+
+// NotZero asserts that v is not the zero value of its type, as reported by
+// [reflect.Value.IsZero]. Optional formatAndArgs can be provided to customize
+// the error message, the first element must be a string, otherwise NotZero
+// panics.
+func NotZero[T Parameter](t TB, v any, formatAndArgs ...any) {
+	t.Helper()
+	if isZero(v) {
+		fail[T](t, formatAndArgs, "got zero value (%T); want non-zero", v)
+	}
+}
+
+// isZero reports whether v is nil or the zero value of its type.
+func isZero(v any) bool {
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || rv.IsZero()
+}
+
+// Contains asserts that container contains elem: a substring, if container is
+// a string; an element, if it is a slice or an array; a key, if it is a map.
+// Optional formatAndArgs can be provided to customize the error message, the
+// first element must be a string, otherwise Contains panics.
+func Contains[T Parameter](t TB, container, elem any, formatAndArgs ...any) {
+	t.Helper()
+	ok, err := contains(container, elem)
+	switch {
+	case err != nil:
+		fail[T](t, formatAndArgs, "%v", err)
+	case !ok && reflect.TypeOf(container).Kind() == reflect.String:
+		fail[T](t, formatAndArgs, "%q does not contain %q", container, elem)
+	case !ok:
+		fail[T](t, formatAndArgs, "%v does not contain %v", container, elem)
+	}
+}
+
+// contains reports whether container contains elem, see [Contains].
+func contains(container, elem any) (bool, error) {
+	cv, ev := reflect.ValueOf(container), reflect.ValueOf(elem)
+	switch cv.Kind() {
+	case reflect.String:
+		if ev.Kind() != reflect.String {
+			return false, fmt.Errorf("cannot look for %T in a string", elem)
+		}
+		return strings.Contains(cv.String(), ev.String()), nil
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < cv.Len(); i++ {
+			if reflect.DeepEqual(cv.Index(i).Interface(), elem) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		if !ev.IsValid() || !ev.Type().AssignableTo(cv.Type().Key()) {
+			return false, fmt.Errorf("cannot look for %T in %T keys", elem, container)
+		}
+		return cv.MapIndex(ev).IsValid(), nil
+	default:
+		return false, fmt.Errorf("unsupported container type %T", container)
+	}
+}
+
+// Len asserts that collection, which must be a string, a slice, an array, a
+// map, or a channel, has n elements. The error message includes the actual
+// contents of the collection. Optional formatAndArgs can be provided to
+// customize the error message, the first element must be a string, otherwise
+// Len panics.
+func Len[T Parameter](t TB, collection any, n int, formatAndArgs ...any) {
+	t.Helper()
+	v := reflect.ValueOf(collection)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+	default:
+		fail[T](t, formatAndArgs, "unsupported collection type %T", collection)
+		return
+	}
+	if v.Len() != n {
+		if v.Kind() == reflect.String {
+			fail[T](t, formatAndArgs, "got len %d (%q); want %d", v.Len(), collection, n)
+			return
+		}
+		fail[T](t, formatAndArgs, "got len %d (%v); want %d", v.Len(), collection, n)
+	}
+}
+
+// isNil reports whether v is nil or holds a nil value of a nillable kind.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// Panics asserts that f panics. Optional formatAndArgs can be provided to
+// customize the error message, the first element must be a string, otherwise
+// Panics panics.
+func Panics[T Parameter](t TB, f func(), formatAndArgs ...any) {
+	t.Helper()
+	if panicked, _ := didPanic(f); !panicked {
+		fail[T](t, formatAndArgs, "got no panic; want panic")
+	}
+}
+
+// NotPanics asserts that f does not panic. Optional formatAndArgs can be
+// provided to customize the error message, the first element must be a string,
+// otherwise NotPanics panics.
+func NotPanics[T Parameter](t TB, f func(), formatAndArgs ...any) {
+	t.Helper()
+	if panicked, v := didPanic(f); panicked {
+		fail[T](t, formatAndArgs, "got panic: %v; want no panic", v)
+	}
+}
+
+// didPanic calls f and reports whether it panicked, along with the value passed
+// to panic.
+func didPanic(f func()) (panicked bool, v any) {
+	// a panic with a nil value cannot be told apart from no panic by recover
+	// before Go 1.21, so track the normal return explicitly.
+	panicked = true
+	defer func() {
+		if panicked {
+			v = recover()
+		}
+	}()
+	f()
+	return false, nil
+}
+
+// Eventually asserts that cond returns true within timeout, calling it
+// immediately and then every tick. If it does not, the error message includes
+// the elapsed time and the number of attempts. Optional formatAndArgs can be
+// provided to customize the error message, the first element must be a
+// string, otherwise Eventually panics.
+func Eventually[T Parameter](t TB, cond func() bool, timeout, tick time.Duration, formatAndArgs ...any) {
+	t.Helper()
+	start := time.Now()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for attempts := 1; ; attempts++ {
+		if cond() {
+			return
+		}
+		select {
+		case <-deadline.C:
+			fail[T](t, formatAndArgs, "condition is not satisfied after %v (%d attempts); want within %v",
+				time.Since(start).Round(time.Millisecond), attempts, timeout)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// isComposite reports whether the values of type t can be diffed by
+// diffValues.
+func isComposite(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// diffValues returns the differences between got and want, which must be of
+// the same type, one per line, prefixed with the path of the differing part.
+func diffValues(path string, got, want reflect.Value) []string {
+	leaf := func() []string {
+		return []string{fmt.Sprintf("%s: got %v; want %v", pathOrRoot(path), got, want)}
+	}
+
+	switch got.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() == want.IsNil() {
+				return nil
+			}
+			return leaf()
+		}
+		if got.Kind() == reflect.Interface && got.Elem().Type() != want.Elem().Type() {
+			return leaf()
+		}
+		return diffValues(path, got.Elem(), want.Elem())
+
+	case reflect.Struct:
+		var lines []string
+		for i := 0; i < got.NumField(); i++ {
+			name := got.Type().Field(i).Name
+			lines = append(lines, diffValues(path+"."+name, got.Field(i), want.Field(i))...)
+		}
+		return lines
+
+	case reflect.Slice, reflect.Array:
+		if got.Kind() == reflect.Slice && got.IsNil() != want.IsNil() {
+			return leaf()
+		}
+		var lines []string
+		if got.Len() != want.Len() {
+			lines = append(lines, fmt.Sprintf("%s: got len %d; want len %d", pathOrRoot(path), got.Len(), want.Len()))
+		}
+		for i := 0; i < got.Len() && i < want.Len(); i++ {
+			lines = append(lines, diffValues(fmt.Sprintf("%s[%d]", path, i), got.Index(i), want.Index(i))...)
+		}
+		return lines
+
+	case reflect.Map:
+		if got.IsNil() != want.IsNil() {
+			return leaf()
+		}
+		var lines []string
+		keys := append(got.MapKeys(), want.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		seen := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			keyPath := fmt.Sprintf("%s[%v]", path, key)
+			if seen[keyPath] {
+				continue
+			}
+			seen[keyPath] = true
+
+			gv, wv := got.MapIndex(key), want.MapIndex(key)
+			switch {
+			case !gv.IsValid():
+				lines = append(lines, fmt.Sprintf("%s: missing; want %v", keyPath, wv))
+			case !wv.IsValid():
+				lines = append(lines, fmt.Sprintf("%s: got %v; want missing", keyPath, gv))
+			default:
+				lines = append(lines, diffValues(keyPath, gv, wv)...)
+			}
+		}
+		return lines
+
+	default:
+		if got.CanInterface() && want.CanInterface() {
+			if reflect.DeepEqual(got.Interface(), want.Interface()) {
+				return nil
+			}
+		} else if fmt.Sprint(got) == fmt.Sprint(want) {
+			// unexported fields cannot be compared directly.
+			return nil
+		}
+		return leaf()
+	}
+}
+
+// pathOrRoot returns path, or a placeholder for the root value, if path is
+// empty.
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
 // fail marks the test as having failed and continues/stops its execution based
 // on T's type.
 func fail[T Parameter](t TB, customFormatAndArgs []any, format string, args ...any) {
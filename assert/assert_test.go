@@ -0,0 +1,295 @@
+package assert_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// fakeTB is a [assert.TB] implementation that records the failure messages.
+type fakeTB struct {
+	errorf string
+	fatalf string
+}
+
+func (*fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...any) { t.errorf = fmt.Sprintf(format, args...) }
+
+func (t *fakeTB) Fatalf(format string, args ...any) { t.fatalf = fmt.Sprintf(format, args...) }
+
+// check runs an assertion against a fakeTB and checks the failure message: an
+// empty message means the assertion must pass.
+func check(t *testing.T, name, message string, assertion func(tb assert.TB)) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		t.Helper()
+		var tb fakeTB
+		assertion(&tb)
+		if tb.errorf != message {
+			t.Errorf("got %q; want %q", tb.errorf, message)
+		}
+	})
+}
+
+func TestFatal(t *testing.T) {
+	var tb fakeTB
+	assert.True[F](&tb, false)
+	if tb.fatalf == "" || tb.errorf != "" {
+		t.Errorf("want Fatalf to be called")
+	}
+}
+
+func TestCustomMessage(t *testing.T) {
+	check(t, "custom", "port 8080 is not open", func(tb assert.TB) {
+		assert.True[E](tb, false, "port %d is not open", 8080)
+	})
+}
+
+func TestEqualFunc(t *testing.T) {
+	utc := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("UTC+3", 3*60*60))
+
+	check(t, "equal", "", func(tb assert.TB) { assert.EqualFunc[E](tb, local, utc, time.Time.Equal) })
+	check(t, "not equal", "got 2022-01-01 12:00:00 +0000 UTC; want 2022-01-01 13:00:00 +0000 UTC", func(tb assert.TB) {
+		assert.EqualFunc[E](tb, utc, utc.Add(time.Hour), time.Time.Equal)
+	})
+}
+
+func TestNotEqual(t *testing.T) {
+	check(t, "different", "", func(tb assert.TB) { assert.NotEqual[E](tb, []int{1}, []int{2}) })
+	check(t, "equal", "got [1]; want a different value", func(tb assert.TB) { assert.NotEqual[E](tb, []int{1}, []int{1}) })
+}
+
+func TestErrorContains(t *testing.T) {
+	err := errors.New("env: PORT is not set")
+	check(t, "contains", "", func(tb assert.TB) { assert.ErrorContains[E](tb, err, "PORT") })
+	check(t, "no error", `got no error; want error containing "PORT"`, func(tb assert.TB) { assert.ErrorContains[E](tb, nil, "PORT") })
+	check(t, "does not contain", `error "env: PORT is not set" does not contain "HOST"`, func(tb assert.TB) {
+		assert.ErrorContains[E](tb, err, "HOST")
+	})
+}
+
+func TestGreater(t *testing.T) {
+	check(t, "greater", "", func(tb assert.TB) { assert.Greater[E](tb, 2, 1) })
+	check(t, "string", "", func(tb assert.TB) { assert.Greater[E](tb, "b", "a") })
+	check(t, "equal", "got 1; want > 1", func(tb assert.TB) { assert.Greater[E](tb, 1, 1) })
+	check(t, "duration", "got 1s; want > 2s", func(tb assert.TB) { assert.Greater[E](tb, time.Second, 2*time.Second) })
+}
+
+func TestLess(t *testing.T) {
+	check(t, "less", "", func(tb assert.TB) { assert.Less[E](tb, 1.5, 2) })
+	check(t, "equal", "got 1; want < 1", func(tb assert.TB) { assert.Less[E](tb, 1, 1) })
+	check(t, "greater", "got 3; want < 2", func(tb assert.TB) { assert.Less[E](tb, uint(3), 2) })
+}
+
+func TestInDelta(t *testing.T) {
+	check(t, "exact", "", func(tb assert.TB) { assert.InDelta[E](tb, 1.0, 1.0, 0) })
+	check(t, "within", "", func(tb assert.TB) { assert.InDelta[E](tb, 0.1+0.2, 0.3, 1e-9) })
+	check(t, "bound", "", func(tb assert.TB) { assert.InDelta[E](tb, uint(1), 3, 2) })
+	check(t, "outside", "got 1.2s; want 1s ± 100ms", func(tb assert.TB) {
+		assert.InDelta[E](tb, 1200*time.Millisecond, time.Second, 100*time.Millisecond)
+	})
+	check(t, "NaN", "got NaN; want 1 ± 1", func(tb assert.TB) { assert.InDelta[E](tb, math.NaN(), 1, 1) })
+}
+
+func TestTrue(t *testing.T) {
+	check(t, "true", "", func(tb assert.TB) { assert.True[E](tb, true) })
+	check(t, "false", "condition is false; want true", func(tb assert.TB) { assert.True[E](tb, false) })
+}
+
+func TestFalse(t *testing.T) {
+	check(t, "false", "", func(tb assert.TB) { assert.False[E](tb, false) })
+	check(t, "true", "condition is true; want false", func(tb assert.TB) { assert.False[E](tb, true) })
+}
+
+func TestNil(t *testing.T) {
+	var (
+		ptr   *int
+		slice []int
+		m     map[string]int
+		err   error
+	)
+	check(t, "untyped nil", "", func(tb assert.TB) { assert.Nil[E](tb, nil) })
+	check(t, "nil error", "", func(tb assert.TB) { assert.Nil[E](tb, err) })
+	check(t, "nil pointer", "", func(tb assert.TB) { assert.Nil[E](tb, ptr) })
+	check(t, "nil slice", "", func(tb assert.TB) { assert.Nil[E](tb, slice) })
+	check(t, "nil map", "", func(tb assert.TB) { assert.Nil[E](tb, m) })
+	check(t, "empty slice", "got []; want nil", func(tb assert.TB) { assert.Nil[E](tb, []int{}) })
+	check(t, "zero int", "got 0; want nil", func(tb assert.TB) { assert.Nil[E](tb, 0) })
+}
+
+func TestNotNil(t *testing.T) {
+	var ptr *int
+	check(t, "value", "", func(tb assert.TB) { assert.NotNil[E](tb, new(int)) })
+	check(t, "untyped nil", "got nil (<nil>); want non-nil", func(tb assert.TB) { assert.NotNil[E](tb, nil) })
+	check(t, "typed nil", "got nil (*int); want non-nil", func(tb assert.TB) { assert.NotNil[E](tb, ptr) })
+}
+
+func TestJSONEqual(t *testing.T) {
+	check(t, "equal", "", func(tb assert.TB) {
+		assert.JSONEqual[E](tb, `{"a": 1, "b": [true, null]}`, `{"b":[true,null],"a":1.0}`)
+	})
+	check(t, "not equal", `got {"a":1,"b":2}; want {"a":1,"b":3}`, func(tb assert.TB) {
+		assert.JSONEqual[E](tb, `{"b": 2, "a": 1}`, `{"a": 1, "b": 3}`)
+	})
+	check(t, "invalid got", "got invalid JSON: unexpected end of JSON input", func(tb assert.TB) {
+		assert.JSONEqual[E](tb, `{`, `{}`)
+	})
+	check(t, "invalid want", "want invalid JSON: invalid character 'x' looking for beginning of value", func(tb assert.TB) {
+		assert.JSONEqual[E](tb, `{}`, `x`)
+	})
+}
+
+func TestElementsMatch(t *testing.T) {
+	check(t, "same order", "", func(tb assert.TB) { assert.ElementsMatch[E](tb, []int{1, 2}, []int{1, 2}) })
+	check(t, "any order", "", func(tb assert.TB) { assert.ElementsMatch[E](tb, []int{2, 1, 2}, []int{2, 2, 1}) })
+	check(t, "empty", "", func(tb assert.TB) { assert.ElementsMatch[E](tb, nil, []string{}) })
+	check(t, "missing", "got [1]; want [2 1] in any order\nmissing: [2]", func(tb assert.TB) {
+		assert.ElementsMatch[E](tb, []int{1}, []int{2, 1})
+	})
+	check(t, "extra", "got [1 1]; want [1] in any order\nextra: [1]", func(tb assert.TB) {
+		assert.ElementsMatch[E](tb, []int{1, 1}, []int{1})
+	})
+	check(t, "missing and extra", "got [a b]; want [b c] in any order\nmissing: [c]\nextra: [a]", func(tb assert.TB) {
+		assert.ElementsMatch[E](tb, []string{"a", "b"}, []string{"b", "c"})
+	})
+}
+
+func TestSubset(t *testing.T) {
+	check(t, "subset", "", func(tb assert.TB) { assert.Subset[E](tb, []int{1, 2, 3}, []int{3, 1}) })
+	check(t, "empty", "", func(tb assert.TB) { assert.Subset[E](tb, []int{1}, nil) })
+	check(t, "not subset", "[1 2] does not contain [3 4]", func(tb assert.TB) {
+		assert.Subset[E](tb, []int{1, 2}, []int{3, 1, 4})
+	})
+}
+
+func TestZero(t *testing.T) {
+	check(t, "zero int", "", func(tb assert.TB) { assert.Zero[E](tb, 0) })
+	check(t, "zero struct", "", func(tb assert.TB) { assert.Zero[E](tb, struct{ A int }{}) })
+	check(t, "nil", "", func(tb assert.TB) { assert.Zero[E](tb, nil) })
+	check(t, "non-zero", `got {1}; want zero value`, func(tb assert.TB) { assert.Zero[E](tb, struct{ A int }{A: 1}) })
+}
+
+func TestNotZero(t *testing.T) {
+	check(t, "non-zero", "", func(tb assert.TB) { assert.NotZero[E](tb, "foo") })
+	check(t, "empty slice", "", func(tb assert.TB) { assert.NotZero[E](tb, []int{}) })
+	check(t, "zero", "got zero value (string); want non-zero", func(tb assert.TB) { assert.NotZero[E](tb, "") })
+	check(t, "nil", "got zero value (<nil>); want non-zero", func(tb assert.TB) { assert.NotZero[E](tb, nil) })
+}
+
+func TestContains(t *testing.T) {
+	check(t, "substring", "", func(tb assert.TB) { assert.Contains[E](tb, "hello world", "world") })
+	check(t, "no substring", `"hello" does not contain "world"`, func(tb assert.TB) { assert.Contains[E](tb, "hello", "world") })
+	check(t, "slice element", "", func(tb assert.TB) { assert.Contains[E](tb, []int{1, 2}, 2) })
+	check(t, "no slice element", "[1 2] does not contain 3", func(tb assert.TB) { assert.Contains[E](tb, []int{1, 2}, 3) })
+	check(t, "map key", "", func(tb assert.TB) { assert.Contains[E](tb, map[string]int{"a": 1}, "a") })
+	check(t, "no map key", "map[a:1] does not contain b", func(tb assert.TB) { assert.Contains[E](tb, map[string]int{"a": 1}, "b") })
+	check(t, "invalid key type", "cannot look for int in map[string]int keys", func(tb assert.TB) { assert.Contains[E](tb, map[string]int{}, 1) })
+	check(t, "unsupported container", "unsupported container type int", func(tb assert.TB) { assert.Contains[E](tb, 1, 1) })
+}
+
+func TestLen(t *testing.T) {
+	check(t, "string", "", func(tb assert.TB) { assert.Len[E](tb, "abc", 3) })
+	check(t, "slice", "", func(tb assert.TB) { assert.Len[E](tb, []int{1, 2}, 2) })
+	check(t, "map", "", func(tb assert.TB) { assert.Len[E](tb, map[string]int{}, 0) })
+	check(t, "wrong string len", `got len 3 ("abc"); want 2`, func(tb assert.TB) { assert.Len[E](tb, "abc", 2) })
+	check(t, "wrong slice len", "got len 2 ([1 2]); want 3", func(tb assert.TB) { assert.Len[E](tb, []int{1, 2}, 3) })
+	check(t, "wrong map len", "got len 1 (map[a:1]); want 0", func(tb assert.TB) { assert.Len[E](tb, map[string]int{"a": 1}, 0) })
+	check(t, "unsupported collection", "unsupported collection type int", func(tb assert.TB) { assert.Len[E](tb, 1, 1) })
+}
+
+func TestPanics(t *testing.T) {
+	check(t, "panic", "", func(tb assert.TB) { assert.Panics[E](tb, func() { panic("oops") }) })
+	check(t, "nil panic", "", func(tb assert.TB) { assert.Panics[E](tb, func() { panic(nil) }) })
+	check(t, "no panic", "got no panic; want panic", func(tb assert.TB) { assert.Panics[E](tb, func() {}) })
+}
+
+func TestNotPanics(t *testing.T) {
+	check(t, "no panic", "", func(tb assert.TB) { assert.NotPanics[E](tb, func() {}) })
+	check(t, "panic", "got panic: oops; want no panic", func(tb assert.TB) { assert.NotPanics[E](tb, func() { panic("oops") }) })
+}
+
+func TestEventually(t *testing.T) {
+	check(t, "immediately", "", func(tb assert.TB) {
+		assert.Eventually[E](tb, func() bool { return true }, time.Second, time.Millisecond)
+	})
+
+	check(t, "after a few attempts", "", func(tb assert.TB) {
+		var attempts int
+		assert.Eventually[E](tb, func() bool { attempts++; return attempts == 3 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("never", func(t *testing.T) {
+		var tb fakeTB
+		assert.Eventually[E](&tb, func() bool { return false }, 20*time.Millisecond, 5*time.Millisecond)
+		if !strings.HasPrefix(tb.errorf, "condition is not satisfied after ") || !strings.HasSuffix(tb.errorf, "; want within 20ms") {
+			t.Errorf("unexpected message %q", tb.errorf)
+		}
+	})
+}
+
+func TestInvalidFormatAndArgs(t *testing.T) {
+	assert.Panics[E](t, func() {
+		assert.True[E](new(fakeTB), false, 42)
+	})
+}
+
+func TestEqual(t *testing.T) {
+	type db struct {
+		Host  string
+		Port  int
+		flags []string
+	}
+	type config struct {
+		DB      db
+		Hosts   []string
+		Labels  map[string]string
+		Timeout *int
+	}
+
+	one, two := 1, 2
+	base := func() config {
+		return config{
+			DB:      db{Host: "localhost", Port: 5432, flags: []string{"a"}},
+			Hosts:   []string{"a", "b"},
+			Labels:  map[string]string{"env": "dev"},
+			Timeout: &one,
+		}
+	}
+
+	check(t, "equal", "", func(tb assert.TB) { assert.Equal[E](tb, base(), base()) })
+	check(t, "scalar", "got 1; want 2", func(tb assert.TB) { assert.Equal[E](tb, 1, 2) })
+	check(t, "different types", "got 1; want <nil>", func(tb assert.TB) { assert.Equal[E, any](tb, 1, nil) })
+
+	got := base()
+	got.DB.Port = 5433
+	got.DB.flags = []string{"b"}
+	got.Hosts = []string{"a"}
+	got.Labels = map[string]string{"env": "prod", "team": "core"}
+	got.Timeout = &two
+
+	check(t, "struct diff", "values of type assert_test.config differ:\n"+
+		"\t.DB.Port: got 5433; want 5432\n"+
+		"\t.DB.flags[0]: got b; want a\n"+
+		"\t.Hosts: got len 1; want len 2\n"+
+		"\t.Labels[env]: got prod; want dev\n"+
+		"\t.Labels[team]: got core; want missing\n"+
+		"\t.Timeout: got 2; want 1", func(tb assert.TB) {
+		assert.Equal[E](tb, got, base())
+	})
+
+	check(t, "slice diff", "values of type []int differ:\n\t[1]: got 2; want 3", func(tb assert.TB) {
+		assert.Equal[E](tb, []int{1, 2}, []int{1, 3})
+	})
+
+	check(t, "nil slice", "values of type []int differ:\n\t(root): got []; want []", func(tb assert.TB) {
+		assert.Equal[E](tb, nil, []int{})
+	})
+}
@@ -0,0 +1,52 @@
+package env
+
+import "reflect"
+
+// DeprecatedUse describes a deprecated name of an environment variable that is
+// set. It is reported by the [AuditDeprecated] function.
+type DeprecatedUse struct {
+	Name        string // Name is the deprecated name of the variable, including prefix.
+	Replacement string // Replacement is the current name of the variable, including prefix.
+	Shadowed    bool   // Shadowed is true, if the variable is also set using its current name, so the deprecated one is ignored.
+}
+
+// AuditDeprecated checks the environment provided by p for the deprecated names
+// of the variables parsed from the fields of dst (see the `deprecated` tag) and
+// reports the ones that are set, along with the names to rename them to, in the
+// order of declaration. Unlike [WithWarnings], it reports the deprecated names
+// even if the variables are also set using their current names. It is useful to
+// track the progress of a migration across services. If p is nil, the default
+// [Provider] is used. See [Describe] for how dst is parsed.
+func AuditDeprecated(p Provider, dst any, opts ...Option) ([]DeprecatedUse, error) {
+	rv := reflect.ValueOf(dst)
+	if !structPtr(rv) {
+		return nil, ErrInvalidArgument
+	}
+
+	l := newLoader(p, opts...)
+
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+
+	var uses []DeprecatedUse
+	for _, v := range vars {
+		if len(v.Deprecated) == 0 {
+			continue
+		}
+
+		_, shadowed := l.provider.LookupEnv(v.Name)
+		for _, name := range v.Deprecated {
+			if _, ok := l.provider.LookupEnv(name); ok {
+				uses = append(uses, DeprecatedUse{Name: name, Replacement: v.Name, Shadowed: shadowed})
+			}
+		}
+	}
+
+	return uses, nil
+}
@@ -0,0 +1,40 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestAuditDeprecated(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.AuditDeprecated(env.Map{}, nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("deprecated names", func(t *testing.T) {
+		m := env.Map{
+			"APP_DATABASE_HOST": "localhost",
+			"APP_DBPORT":        "5432",
+			"APP_DB_PORT":       "5433",
+			"APP_USER":          "admin",
+		}
+
+		var cfg struct {
+			Host string `env:"DB_HOST" deprecated:"DATABASE_HOST,DBHOST"`
+			Port int    `env:"DB_PORT" deprecated:"DBPORT"`
+			User string `env:"DB_USER" deprecated:"USER"`
+			Pass string `env:"DB_PASS" deprecated:"PASS"`
+		}
+
+		uses, err := env.AuditDeprecated(m, &cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, uses, []env.DeprecatedUse{
+			{Name: "APP_DATABASE_HOST", Replacement: "APP_DB_HOST"},
+			{Name: "APP_DBPORT", Replacement: "APP_DB_PORT", Shadowed: true},
+			{Name: "APP_USER", Replacement: "APP_DB_USER"},
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TagOption is a tag-level option that can be passed to [Bind]/[BindFrom].
+type TagOption string
+
+// The tag-level options supported by [Bind]/[BindFrom]. See [Load]
+// documentation for details.
+const (
+	Required TagOption = "required"
+	Expand   TagOption = "expand"
+	Secret   TagOption = "secret"
+)
+
+// Bind loads the environment variable named by name into dst using the default
+// [Provider] as its source. It is a shortcut for small programs and tests that
+// need only a few variables and do not want to declare a config struct. dst
+// must be a non-nil pointer, otherwise Bind returns [ErrInvalidArgument]. The
+// current value of dst is used as the default one:
+//
+//	port := 8080
+//	if err := env.Bind(&port, "PORT"); err != nil {
+//		// handle error
+//	}
+//
+// See [Load] documentation for the supported types and options.
+func Bind(dst any, name string, opts ...TagOption) error {
+	return BindFrom(nil, dst, name, opts...)
+}
+
+// BindFrom loads the environment variable named by name into dst using the
+// specified [Provider] as its source. If p is nil, the default [Provider] is
+// used. See [Bind] documentation for more details.
+func BindFrom(p Provider, dst any, name string, opts ...TagOption) error {
+	rv := reflect.ValueOf(dst)
+	if !rv.IsValid() || rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidArgument
+	}
+
+	tag := []string{name}
+	for _, opt := range opts {
+		tag = append(tag, string(opt))
+	}
+
+	// build a single-field struct on the fly to reuse the loading machinery.
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: rv.Elem().Type(),
+		Tag:  reflect.StructTag("env:" + strconv.Quote(strings.Join(tag, ","))),
+	}})
+
+	sv := reflect.New(typ)
+	sv.Elem().Field(0).Set(rv.Elem())
+	if err := LoadFrom(p, sv.Interface()); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(sv.Elem().Field(0))
+	return nil
+}
+
+// Get returns the value of the environment variable named by name parsed as T,
+// using the default [Provider] as its source. If the variable is not set or
+// cannot be parsed, def is returned. Use [GetOrErr] to handle errors
+// explicitly.
+func Get[T any](name string, def T) T {
+	v := def
+	if err := Bind(&v, name); err != nil {
+		return def
+	}
+	return v
+}
+
+// GetOrErr returns the value of the environment variable named by name parsed
+// as T, using the default [Provider] as its source. If the variable is not set,
+// an error of type [NotSetError] is returned.
+func GetOrErr[T any](name string) (T, error) {
+	var v T
+	if err := Bind(&v, name, Required); err != nil {
+		return v, err
+	}
+	return v, nil
+}
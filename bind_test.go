@@ -0,0 +1,79 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestBindFrom(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		test := func(name string, dst any) {
+			t.Run(name, func(t *testing.T) {
+				err := env.BindFrom(env.Map{}, dst, "PORT")
+				assert.IsErr[E](t, err, env.ErrInvalidArgument)
+			})
+		}
+
+		test("nil", nil)
+		test("not a pointer", 0)
+		test("nil pointer", (*int)(nil))
+	})
+
+	t.Run("set", func(t *testing.T) {
+		m := env.Map{"PORT": "8080", "TIMEOUT": "1s"}
+
+		var port int
+		err := env.BindFrom(m, &port, "PORT", env.Required)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, port, 8080)
+
+		var timeout time.Duration
+		err = env.BindFrom(m, &timeout, "TIMEOUT")
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, timeout, time.Second)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		host := "localhost"
+		err := env.BindFrom(env.Map{}, &host, "HOST")
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, host, "localhost")
+	})
+
+	t.Run("required", func(t *testing.T) {
+		var notSetErr *env.NotSetError
+
+		var port int
+		err := env.BindFrom(env.Map{}, &port, "PORT", env.Required)
+		assert.AsErr[F](t, err, &notSetErr)
+		assert.Equal[E](t, notSetErr.Names, []string{"PORT"})
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	t.Setenv("TIMEOUT", "-")
+
+	assert.Equal[E](t, env.Get("PORT", 0), 8080)
+	assert.Equal[E](t, env.Get("HOST", "localhost"), "localhost")
+	assert.Equal[E](t, env.Get("TIMEOUT", time.Second), time.Second)
+}
+
+func TestGetOrErr(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	t.Setenv("TIMEOUT", "-")
+
+	port, err := env.GetOrErr[int]("PORT")
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, port, 8080)
+
+	_, err = env.GetOrErr[string]("HOST")
+	assert.AsErr[E](t, err, new(*env.NotSetError))
+
+	_, err = env.GetOrErr[time.Duration]("TIMEOUT")
+	assert.ErrorContains[E](t, err, "invalid duration")
+}
@@ -0,0 +1,147 @@
+package env
+
+import (
+	"sync"
+	"time"
+)
+
+// ErrorProvider is an optional interface that a [Provider] can implement to
+// report lookup failures (e.g. network errors of a remote provider) separately
+// from the variables that are not set. It is used by [Breaker].
+type ErrorProvider interface {
+	Provider
+	// LookupEnvErr is like LookupEnv, but also returns an error, if the lookup
+	// has failed.
+	LookupEnvErr(key string) (value string, ok bool, err error)
+}
+
+// Breaker returns a [Middleware] implementing the circuit breaker pattern for
+// flaky remote providers. The wrapped [Provider] must implement the
+// [ErrorProvider] interface, otherwise its lookups are never considered failed.
+//
+// The values successfully looked up are cached. If a lookup fails, the cached
+// value is returned instead (or the variable is reported as not set, if there
+// is no cached value). After the provided number of consecutive failures, the
+// breaker opens: the provider is not called for the cooldown period, and only
+// the cached values are served. After the cooldown, the next lookup is passed
+// through to the provider as a probe, while the concurrent lookups are still
+// served from the cache: if the probe succeeds, the breaker closes, otherwise
+// it opens again. The cached values are reported with the source they were
+// looked up from, see [WithSources] for details. The returned [Provider] is
+// safe for concurrent use, if the wrapped one is.
+//
+//	p := env.Chain(remote, env.Breaker(3, 30*time.Second))
+func Breaker(failures int, cooldown time.Duration) Middleware {
+	return func(p Provider) Provider {
+		return &breakerProvider{
+			provider: p,
+			failures: failures,
+			cooldown: cooldown,
+			cache:    make(map[string]cachedValue),
+		}
+	}
+}
+
+// breakerProvider is the [Provider] returned by [Breaker].
+type breakerProvider struct {
+	provider Provider
+	failures int
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	failed    int       // the number of consecutive failures.
+	openUntil time.Time // the end of the cooldown, if the breaker is open.
+	probing   bool      // whether a lookup after the cooldown is in progress.
+	cache     map[string]cachedValue
+}
+
+// cachedValue is a value cached by [Breaker] along with its source.
+type cachedValue struct {
+	value  string
+	source string
+}
+
+// LookupEnv implements the [Provider] interface.
+func (b *breakerProvider) LookupEnv(key string) (string, bool) {
+	value, _, ok, _ := b.lookup(key)
+	return value, ok
+}
+
+// LookupEnvErr implements the [ErrorProvider] interface. The error of the
+// wrapped provider is returned along with the cached value, if any.
+func (b *breakerProvider) LookupEnvErr(key string) (string, bool, error) {
+	value, _, ok, err := b.lookup(key)
+	return value, ok, err
+}
+
+// lookupEnvSource implements the sourceProvider interface. The cached values
+// are reported with the source they were looked up from.
+func (b *breakerProvider) lookupEnvSource(key string) (string, string, bool) {
+	value, source, ok, _ := b.lookup(key)
+	return value, source, ok
+}
+
+// lookup is the implementation of the lookup methods.
+func (b *breakerProvider) lookup(key string) (string, string, bool, error) {
+	b.mu.Lock()
+	switch {
+	case b.probing || time.Now().Before(b.openUntil):
+		// open, or half-open with another lookup probing the provider.
+		c, ok := b.cache[key]
+		b.mu.Unlock()
+		return c.value, c.source, ok, nil
+	case !b.openUntil.IsZero():
+		// half-open: this lookup is the probe.
+		b.probing = true
+	}
+	b.mu.Unlock()
+
+	value, source, ok, err := lookupSourceErr(b.provider, key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+
+	if err != nil {
+		if b.failed++; b.failed >= b.failures {
+			b.openUntil = time.Now().Add(b.cooldown)
+		}
+		c, ok := b.cache[key]
+		return c.value, c.source, ok, err
+	}
+
+	b.failed = 0
+	b.openUntil = time.Time{}
+	if ok {
+		b.cache[key] = cachedValue{value: value, source: source}
+	} else {
+		delete(b.cache, key)
+	}
+	return value, source, ok, nil
+}
+
+// Keys implements the [Lister] interface.
+func (b *breakerProvider) Keys() []string { return listKeys(b.provider) }
+
+// lookupErr retrieves the value of the environment variable named by the key
+// using p, reporting the lookup failure, if p implements the [ErrorProvider]
+// interface.
+func lookupErr(p Provider, key string) (string, bool, error) {
+	if ep, ok := p.(ErrorProvider); ok {
+		return ep.LookupEnvErr(key)
+	}
+	value, ok := p.LookupEnv(key)
+	return value, ok, nil
+}
+
+// lookupSourceErr is like [lookupErr], but also reports the source of the
+// value. The source of the values looked up using an [ErrorProvider] is
+// always "env".
+func lookupSourceErr(p Provider, key string) (string, string, bool, error) {
+	if ep, ok := p.(ErrorProvider); ok {
+		value, ok, err := ep.LookupEnvErr(key)
+		return value, sourceEnv, ok, err
+	}
+	value, source, ok := lookupSource(p, key)
+	return value, source, ok, nil
+}
@@ -0,0 +1,185 @@
+package env_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// flakyProvider is an [env.ErrorProvider] that fails on demand.
+type flakyProvider struct {
+	mu    sync.Mutex
+	m     env.Map
+	fail  bool
+	calls int
+}
+
+func (p *flakyProvider) LookupEnv(key string) (string, bool) {
+	value, ok, _ := p.LookupEnvErr(key)
+	return value, ok
+}
+
+func (p *flakyProvider) LookupEnvErr(key string) (string, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.fail {
+		return "", false, errors.New("connection refused")
+	}
+	value, ok := p.m.LookupEnv(key)
+	return value, ok, nil
+}
+
+func (p *flakyProvider) set(fail bool) (calls int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fail = fail
+	return p.calls
+}
+
+func TestBreaker(t *testing.T) {
+	fp := &flakyProvider{m: env.Map{"HOST": "localhost"}}
+	p := env.Chain(fp, env.Breaker(2, 50*time.Millisecond))
+	ep := p.(env.ErrorProvider)
+
+	// closed: the values are passed through and cached.
+	value, ok := p.LookupEnv("HOST")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, value, "localhost")
+
+	// failing: the cached values are served along with the error.
+	fp.set(true)
+	value, ok, err := ep.LookupEnvErr("HOST")
+	assert.ErrorContains[E](t, err, "connection refused")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, value, "localhost")
+
+	_, ok = p.LookupEnv("PORT")
+	assert.False[E](t, ok)
+
+	// open: the provider is not called anymore.
+	calls := fp.set(true)
+	value, ok = p.LookupEnv("HOST")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, value, "localhost")
+	assert.Equal[E](t, fp.set(true), calls)
+
+	// half-open after the cooldown: a failure opens the breaker again...
+	time.Sleep(60 * time.Millisecond)
+	_, _ = p.LookupEnv("HOST")
+	calls = fp.set(false)
+	_, _ = p.LookupEnv("HOST")
+	assert.Equal[E](t, fp.set(false), calls)
+
+	// ...and a success closes it.
+	time.Sleep(60 * time.Millisecond)
+	_, _ = p.LookupEnv("HOST")
+	calls = fp.set(false)
+	_, _ = p.LookupEnv("HOST")
+	assert.Equal[E](t, fp.set(false), calls+1)
+}
+
+// probeProvider is an [env.ErrorProvider] that fails until told otherwise and
+// blocks the first successful lookup until it is released.
+type probeProvider struct {
+	calls    atomic.Int32
+	fail     atomic.Bool
+	entered  chan struct{}
+	released chan struct{}
+}
+
+func (p *probeProvider) LookupEnv(key string) (string, bool) {
+	value, ok, _ := p.LookupEnvErr(key)
+	return value, ok
+}
+
+func (p *probeProvider) LookupEnvErr(string) (string, bool, error) {
+	if p.calls.Add(1); p.fail.Load() {
+		return "", false, errors.New("connection refused")
+	}
+	select {
+	case p.entered <- struct{}{}:
+		<-p.released
+	default:
+	}
+	return "localhost", true, nil
+}
+
+func TestBreakerProbe(t *testing.T) {
+	pp := &probeProvider{entered: make(chan struct{}), released: make(chan struct{})}
+	pp.fail.Store(true)
+	p := env.Chain(pp, env.Breaker(1, 10*time.Millisecond))
+
+	_, ok := p.LookupEnv("HOST")
+	assert.False[F](t, ok)
+
+	// half-open after the cooldown: only the probe reaches the provider.
+	time.Sleep(20 * time.Millisecond)
+	pp.fail.Store(false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		value, _ := p.LookupEnv("HOST")
+		assert.Equal[E](t, value, "localhost")
+	}()
+
+	// the other lookups are made while the probe is blocked in the provider.
+	<-pp.entered
+	for i := 0; i < 3; i++ {
+		_, ok = p.LookupEnv("HOST")
+		assert.False[E](t, ok)
+	}
+	assert.Equal[E](t, pp.calls.Load(), int32(2))
+
+	// the probe succeeds and closes the breaker.
+	close(pp.released)
+	<-done
+	_, ok = p.LookupEnv("HOST")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, pp.calls.Load(), int32(3))
+}
+
+func TestBreakerLoad(t *testing.T) {
+	fp := &flakyProvider{m: env.Map{"HOST": "localhost", "PORT": "8080"}}
+	p := env.Chain(fp, env.Breaker(1, time.Minute))
+
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+
+	var cfg config
+	err := env.LoadFrom(p, &cfg)
+	assert.NoErr[F](t, err)
+
+	// the provider is down, but the config can be reloaded from the cache.
+	fp.set(true)
+	var reloaded config
+	err = env.LoadFrom(p, &reloaded)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, reloaded, cfg)
+}
+
+func TestBreakerSources(t *testing.T) {
+	p := env.Chain(env.Named("vault", env.Map{"HOST": "localhost"}), env.Breaker(1, time.Minute))
+
+	var cfg struct {
+		Host string `env:"HOST"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(p, &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, sources["HOST"], "vault")
+
+	plan, err := env.Plan(p, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[F](t, len(plan), 1)
+	assert.Equal[E](t, plan[0].Source, "vault")
+}
@@ -0,0 +1,70 @@
+package env
+
+import "reflect"
+
+// Clone returns a deep copy of the provided config, including the values
+// referenced by pointer, slice, and map fields, so that it can be safely
+// handed out as an immutable snapshot, e.g. by hot-reload code. T is usually a
+// struct or a struct pointer. Unexported fields are copied shallowly. Clone
+// does not support cyclic data structures.
+func Clone[T any](cfg T) T {
+	src := reflect.ValueOf(&cfg).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src)
+	return dst.Interface().(T)
+}
+
+// deepCopy recursively copies src into dst. Both values must have the same
+// type and dst must be settable.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			key := reflect.New(iter.Key().Type()).Elem()
+			deepCopy(key, iter.Key())
+			value := reflect.New(iter.Value().Type()).Elem()
+			deepCopy(value, iter.Value())
+			dst.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		// copy the whole struct first to preserve unexported fields.
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
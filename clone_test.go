@@ -0,0 +1,57 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestClone(t *testing.T) {
+	type config struct {
+		Host     string
+		Timeouts []time.Duration
+		Labels   map[string]string
+		TLS      *struct{ Cert string }
+		Any      any
+		Ports    [2]int
+	}
+
+	orig := config{
+		Host:     "localhost",
+		Timeouts: []time.Duration{time.Second},
+		Labels:   map[string]string{"env": "prod"},
+		TLS:      &struct{ Cert string }{Cert: "cert.pem"},
+		Any:      []int{1},
+		Ports:    [2]int{8080, 8081},
+	}
+
+	clone := env.Clone(orig)
+	assert.Equal[F](t, clone, orig)
+
+	// modifying the clone must not affect the original.
+	clone.Timeouts[0] = time.Minute
+	clone.Labels["env"] = "dev"
+	clone.TLS.Cert = "other.pem"
+	clone.Any.([]int)[0] = 2
+	clone.Ports[0] = 0
+
+	assert.Equal[E](t, orig.Timeouts[0], time.Second)
+	assert.Equal[E](t, orig.Labels["env"], "prod")
+	assert.Equal[E](t, orig.TLS.Cert, "cert.pem")
+	assert.Equal[E](t, orig.Any.([]int)[0], 1)
+	assert.Equal[E](t, orig.Ports[0], 8080)
+
+	t.Run("pointer", func(t *testing.T) {
+		clone := env.Clone(&orig)
+		clone.Host = "example.com"
+		assert.Equal[E](t, orig.Host, "localhost")
+	})
+
+	t.Run("nil values", func(t *testing.T) {
+		var empty config
+		assert.Equal[E](t, env.Clone(empty), empty)
+	})
+}
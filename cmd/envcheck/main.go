@@ -0,0 +1,208 @@
+// Command envcheck validates an environment against a manifest describing the
+// configuration of an application. It reports the missing, invalid, and
+// unknown environment variables and exits with a non-zero code if there are
+// any, which makes it suitable as a pre-deploy gate.
+//
+// The manifest is a JSON Schema generated from the config struct by
+// [env.WriteJSONSchema]:
+//
+//	envcheck -schema env.schema.json [-dotenv .env] [-prefix APP_] [-sep " "]
+//
+// By default, the current environment is checked. If the -dotenv flag is
+// provided, the dotenv file is checked instead. The unknown variables are
+// reported only if they start with the prefix specified by the -prefix flag, or
+// if a dotenv file is checked. The deprecated names of the variables that are
+// set are always reported, along with the names to rename them to, which makes
+// envcheck also useful to track the progress of a migration; such variables
+// still satisfy the requirement. The values are validated against the Go types
+// of the struct fields, e.g. 300 is invalid for an int8 field.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/internal/manifest"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Environ(), os.Stdout, os.Stderr))
+}
+
+// run runs the command and returns the exit code: 0 if the environment is
+// valid, 1 if there are issues, and 2 if the command itself fails.
+func run(args, environ []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("schema", "", "path to the JSON Schema generated by env.WriteJSONSchema (required)")
+	dotenvPath := fs.String("dotenv", "", "path to the dotenv file to check instead of the current environment")
+	prefix := fs.String("prefix", "", "report unknown variables starting with the prefix")
+	sep := fs.String("sep", " ", "separator of slice values")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaPath == "" {
+		fmt.Fprintln(stderr, "envcheck: the -schema flag is required")
+		fs.Usage()
+		return 2
+	}
+
+	s, err := manifest.Read(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "envcheck: %v\n", err)
+		return 2
+	}
+
+	vars := make(env.Map)
+	if *dotenvPath != "" {
+		if vars, err = env.ReadDotenv(*dotenvPath); err != nil {
+			fmt.Fprintf(stderr, "envcheck: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				vars[k] = v
+			}
+		}
+	}
+
+	issues := check(s, vars, *prefix, *sep, *dotenvPath != "")
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// check checks vars against s and returns the issues found, sorted by the
+// names of the variables. If all is true, the unknown variables are reported
+// regardless of the prefix.
+func check(s *manifest.Schema, vars env.Map, prefix, sep string, all bool) []string {
+	var issues []string
+
+	for _, name := range s.Required {
+		if !isSet(vars, name, s.Properties[name]) {
+			issues = append(issues, fmt.Sprintf("%s: missing", name))
+		}
+	}
+
+	replacements := make(map[string]string)
+	for name, prop := range s.Properties {
+		for _, old := range prop.Deprecated {
+			replacements[old] = name
+		}
+	}
+
+	for name, value := range vars {
+		prop, ok := s.Properties[name]
+		switch {
+		case !ok && replacements[name] != "":
+			issues = append(issues, fmt.Sprintf("%s: deprecated, rename to %s", name, replacements[name]))
+		case !ok && strings.HasPrefix(name, prefix) && (all || prefix != ""):
+			issues = append(issues, fmt.Sprintf("%s: unknown", name))
+		case !ok:
+			continue
+		default:
+			if err := validate(prop, value, sep); err != nil {
+				issues = append(issues, fmt.Sprintf("%s: invalid: %v", name, err))
+			}
+		}
+	}
+
+	sort.Strings(issues)
+	return issues
+}
+
+// isSet reports whether the variable named by name is set in vars, either
+// under its name or one of its deprecated names listed in p (p may be nil).
+func isSet(vars env.Map, name string, p *manifest.Property) bool {
+	if _, ok := vars[name]; ok {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	for _, old := range p.Deprecated {
+		if _, ok := vars[old]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validate reports whether value conforms to p. sep is used to split slice
+// values.
+func validate(p *manifest.Property, value, sep string) error {
+	if p.Type == "array" {
+		if p.Items == nil {
+			return nil
+		}
+		for _, elem := range strings.Split(value, sep) {
+			if err := validate(p.Items, elem, sep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	parsed, err := p.Parse(value, sep)
+	if err != nil {
+		return err
+	}
+
+	var number float64
+	switch parsed := parsed.(type) {
+	case int64:
+		number = float64(parsed)
+	case uint64:
+		number = float64(parsed)
+	case float64:
+		number = parsed
+	}
+
+	if p.Minimum != nil && number < *p.Minimum {
+		return fmt.Errorf("%s is less than %v", value, *p.Minimum)
+	}
+	if p.Maximum != nil && number > *p.Maximum {
+		return fmt.Errorf("%s is greater than %v", value, *p.Maximum)
+	}
+
+	if len(p.Enum) > 0 && !allowed(p, value, number) {
+		return errors.New(value + " is not one of the allowed values")
+	}
+
+	return nil
+}
+
+// allowed reports whether value (parsed as number, if p is numeric) is one of
+// the values listed in the enum of p.
+func allowed(p *manifest.Property, value string, number float64) bool {
+	for _, e := range p.Enum {
+		switch e := e.(type) {
+		case float64:
+			if e == number {
+				return true
+			}
+		case bool:
+			if b, _ := strconv.ParseBool(value); b == e {
+				return true
+			}
+		default:
+			if fmt.Sprint(e) == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRun(t *testing.T) {
+	var cfg struct {
+		Host    string        `env:"APP_HOST,required" deprecated:"APP_HOSTNAME"`
+		Port    int           `env:"APP_PORT" default:"8080" min:"1" max:"65535"`
+		Level   string        `env:"APP_LEVEL" default:"info" enum:"debug,info"`
+		Timeout time.Duration `env:"APP_TIMEOUT" default:"5s"`
+		Ports   []uint16      `env:"APP_PORTS"`
+		Retries int8          `env:"APP_RETRIES"`
+	}
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "env.schema.json")
+
+	f, err := os.Create(schemaPath)
+	assert.NoErr[F](t, err)
+	err = env.WriteJSONSchema(f, &cfg)
+	assert.NoErr[F](t, err)
+	err = f.Close()
+	assert.NoErr[F](t, err)
+
+	test := func(name string, args, environ []string, code int, output string) {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal[E](t, run(args, environ, &stdout, &stderr), code)
+			assert.Equal[E](t, stdout.String(), output)
+		})
+	}
+
+	test("valid", []string{"-schema", schemaPath},
+		[]string{"APP_HOST=localhost", "APP_PORT=80", "APP_PORTS=80 443", "HOME=/root"},
+		0, "")
+
+	test("invalid", []string{"-schema", schemaPath, "-prefix", "APP_"},
+		[]string{"APP_HOSTNAME=localhost", "APP_PORT=0", "APP_LEVEL=warn", "APP_TIMEOUT=5", "APP_PORTS=80 -1", "APP_RETRIES=300", "APP_UNKNOWN=1", "HOME=/root"},
+		1, "APP_HOSTNAME: deprecated, rename to APP_HOST\n"+
+			"APP_LEVEL: invalid: warn is not one of the allowed values\n"+
+			"APP_PORT: invalid: 0 is less than 1\n"+
+			"APP_PORTS: invalid: strconv.ParseUint: parsing \"-1\": invalid syntax\n"+
+			"APP_RETRIES: invalid: strconv.ParseInt: parsing \"300\": value out of range\n"+
+			"APP_TIMEOUT: invalid: time: missing unit in duration \"5\"\n"+
+			"APP_UNKNOWN: unknown\n")
+
+	test("missing", []string{"-schema", schemaPath},
+		[]string{"HOME=/root"},
+		1, "APP_HOST: missing\n")
+
+	test("out of range", []string{"-schema", schemaPath},
+		[]string{"APP_HOST=localhost", "APP_PORTS=80 70000"},
+		1, "APP_PORTS: invalid: strconv.ParseUint: parsing \"70000\": value out of range\n")
+
+	dotenvPath := filepath.Join(dir, ".env")
+	err = os.WriteFile(dotenvPath, []byte("APP_HOST=localhost\nDEBUG=true\n"), 0o600)
+	assert.NoErr[F](t, err)
+
+	test("dotenv", []string{"-schema", schemaPath, "-dotenv", dotenvPath},
+		[]string{"APP_PORT=-"},
+		1, "DEBUG: unknown\n")
+
+	test("no schema", nil, nil, 2, "")
+	test("schema not found", []string{"-schema", filepath.Join(dir, "missing.json")}, nil, 2, "")
+}
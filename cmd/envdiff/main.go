@@ -0,0 +1,191 @@
+// Command envdiff compares two environments against a manifest describing the
+// configuration of an application and prints the variables whose effective
+// values differ, which is useful to debug issues like "works in staging":
+//
+//	envdiff -schema env.schema.json staging.env prod.env
+//
+// The manifest is a JSON Schema generated from the config struct by
+// [env.WriteJSONSchema]. Each environment is either a dotenv file or "@env" for
+// the current environment. The effective value of a variable is its value (or
+// the value of its first deprecated name that is set), or its default value, if
+// it is not set. The values are compared after parsing them as the types of the
+// struct fields, so e.g. "05432" and "5432" or "1" and "true" are the same. The
+// differences are printed one per line, along with the paths of the struct
+// fields:
+//
+//	DB.Port (DB_PORT): "5432" (default) -> "5433"
+//
+// The values of the variables marked as secret are masked. The exit code is 0
+// if there are no differences, 1 if there are, and 2 if the command fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/internal/manifest"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Environ(), os.Stdout, os.Stderr))
+}
+
+// run runs the command and returns the exit code.
+func run(args, environ []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envdiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("schema", "", "path to the JSON Schema generated by env.WriteJSONSchema (required)")
+	sep := fs.String("sep", " ", "separator of slice values")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaPath == "" || fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "envdiff: the -schema flag and two environments are required")
+		fs.Usage()
+		return 2
+	}
+
+	s, err := manifest.Read(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "envdiff: %v\n", err)
+		return 2
+	}
+
+	var envs [2]env.Map
+	for i, name := range fs.Args() {
+		if envs[i], err = readEnv(name, environ); err != nil {
+			fmt.Fprintf(stderr, "envdiff: %v\n", err)
+			return 2
+		}
+	}
+
+	changes := diff(s, envs[0], envs[1], *sep)
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// readEnv reads the environment named by name: either a dotenv file, or the
+// current environment, if name is "@env".
+func readEnv(name string, environ []string) (env.Map, error) {
+	if name != "@env" {
+		return env.ReadDotenv(name)
+	}
+
+	m := make(env.Map, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			m[k] = v
+		}
+	}
+	return m, nil
+}
+
+// diff compares the effective values of the variables described by s in a and
+// b, and returns the differences sorted by the names of the variables.
+func diff(s *manifest.Schema, a, b env.Map, sep string) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		prop := s.Properties[name]
+		oldValue, oldDefault, oldSet := effectiveValue(prop, a, name, sep)
+		newValue, newDefault, newSet := effectiveValue(prop, b, name, sep)
+
+		if oldSet == newSet && equal(prop, oldValue, newValue, sep) {
+			continue
+		}
+
+		field := name
+		if prop.GoField != "" {
+			field = prop.GoField + " (" + name + ")"
+		}
+
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", field,
+			formatValue(prop, oldValue, oldDefault, oldSet),
+			formatValue(prop, newValue, newDefault, newSet)))
+	}
+
+	return changes
+}
+
+// equal reports whether the values a and b of a variable described by p are
+// the same once parsed. The values that cannot be parsed are compared as is.
+func equal(p *manifest.Property, a, b, sep string) bool {
+	if a == b {
+		return true
+	}
+	x, err := p.Parse(a, sep)
+	if err != nil {
+		return false
+	}
+	y, err := p.Parse(b, sep)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// effectiveValue returns the effective value of the variable named by name in
+// m: either its value (or the value of its first deprecated name that is set),
+// or its default value described by p. It also reports whether the default
+// value is used, and whether there is any value at all.
+func effectiveValue(p *manifest.Property, m env.Map, name, sep string) (value string, isDefault, ok bool) {
+	for _, key := range append([]string{name}, p.Deprecated...) {
+		if value, ok := m[key]; ok {
+			return value, false, true
+		}
+	}
+	if p.Default == nil {
+		return "", false, false
+	}
+	return formatDefault(p.Default, sep), true, true
+}
+
+// formatDefault formats a default value decoded from JSON as a string.
+func formatDefault(v any, sep string) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		elems := make([]string, len(v))
+		for i, elem := range v {
+			elems[i] = formatDefault(elem, sep)
+		}
+		return strings.Join(elems, sep)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatValue formats the effective value of a variable for the output.
+func formatValue(p *manifest.Property, value string, isDefault, ok bool) string {
+	switch {
+	case !ok:
+		return "<unset>"
+	case p.WriteOnly:
+		value = "***"
+	default:
+		value = strconv.Quote(value)
+	}
+	if isDefault {
+		value += " (default)"
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRun(t *testing.T) {
+	var cfg struct {
+		DB struct {
+			Host     string `env:"DB_HOST,required" deprecated:"DATABASE_HOST"`
+			Port     int    `env:"DB_PORT" default:"5432"`
+			Password string `env:"DB_PASSWORD,secret"`
+		}
+		Hosts []string `env:"HOSTS" default:"a b"`
+		Debug bool     `env:"DEBUG"`
+	}
+
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(data), 0o600)
+		assert.NoErr[F](t, err)
+		return path
+	}
+
+	var schema bytes.Buffer
+	err := env.WriteJSONSchema(&schema, &cfg)
+	assert.NoErr[F](t, err)
+	schemaPath := write("env.schema.json", schema.String())
+
+	staging := write("staging.env", "DB_HOST=staging.local\nDB_PASSWORD=foo\nHOSTS=a b\nDEBUG=true\n")
+	prod := write("prod.env", "DB_HOST=prod.local\nDB_PORT=5433\nDB_PASSWORD=bar\n")
+
+	test := func(name string, args, environ []string, code int, output string) {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal[E](t, run(args, environ, &stdout, &stderr), code)
+			assert.Equal[E](t, stdout.String(), output)
+		})
+	}
+
+	test("different", []string{"-schema", schemaPath, staging, prod}, nil, 1,
+		`DB.Host (DB_HOST): "staging.local" -> "prod.local"`+"\n"+
+			`DB.Password (DB_PASSWORD): *** -> ***`+"\n"+
+			`DB.Port (DB_PORT): "5432" (default) -> "5433"`+"\n"+
+			`Debug (DEBUG): "true" -> "false" (default)`+"\n")
+
+	test("same", []string{"-schema", schemaPath, staging, "@env"},
+		[]string{"DB_HOST=staging.local", "DB_PORT=5432", "DB_PASSWORD=foo", "DEBUG=true"}, 0, "")
+
+	test("unset", []string{"-schema", schemaPath, prod, "@env"}, nil, 1,
+		`DB.Host (DB_HOST): "prod.local" -> <unset>`+"\n"+
+			`DB.Password (DB_PASSWORD): *** -> <unset>`+"\n"+
+			`DB.Port (DB_PORT): "5433" -> "5432" (default)`+"\n")
+
+	test("same parsed values", []string{"-schema", schemaPath, staging, "@env"},
+		[]string{"DATABASE_HOST=staging.local", "DB_PORT=05432", "DB_PASSWORD=foo", "HOSTS=a b", "DEBUG=1"}, 0, "")
+
+	test("no environments", []string{"-schema", schemaPath}, nil, 2, "")
+}
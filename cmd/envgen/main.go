@@ -0,0 +1,421 @@
+// Command envgen generates reflection-free loaders for config structs. For each
+// provided struct type, it emits a typed function that loads environment
+// variables into a new value of the type, the same way [env.LoadFrom] does:
+//
+//	func LoadConfig(p env.Provider) (Config, error)
+//
+// The generated code calls the parsers directly, which makes it suitable for
+// hot paths, TinyGo targets, and binaries that want the unused parsers to be
+// eliminated. It is intended to be used with go generate:
+//
+//	//go:generate go run github.com/junk1tm/env/cmd/envgen -type Config
+//
+// The following flags are supported:
+//
+//	-type             comma-separated list of struct type names (required)
+//	-output           output file name (default: <type>_env.go)
+//	-prefix           prefix added to the name of each environment variable
+//	-sep              separator of slice values (default: " ")
+//	-expansion-style  syntax of the references expanded by the expand option:
+//	                  unix, windows, or both (default: unix)
+//
+// The `env` (including the required and expand options), `default`, and
+// `deprecated` tags are supported. The values are expanded with [env.ExpandValue]
+// and the parsing errors are reported as [env.ParseError], so the generated
+// code behaves the same as [env.LoadFrom]. The fields must be of the basic
+// types (string, bool, int, uint, and float of any size), [time.Duration], or
+// slices of them; nested structs declared in the same package are parsed
+// recursively. The types not supported by envgen (e.g.
+// [encoding.TextUnmarshaler] implementations or structs embedded from other
+// packages) are reported as errors, use [env.Load] for them instead.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], ".", os.Stderr))
+}
+
+// run runs the command in dir and returns the exit code.
+func run(args []string, dir string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	types := fs.String("type", "", "comma-separated list of struct type names (required)")
+	output := fs.String("output", "", "output file name (default: <type>_env.go)")
+	prefix := fs.String("prefix", "", "prefix added to the name of each environment variable")
+	sep := fs.String("sep", " ", "separator of slice values")
+	style := fs.String("expansion-style", "unix", "syntax of the references expanded by the expand option: unix, windows, or both")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *types == "" {
+		fmt.Fprintln(stderr, "envgen: the -type flag is required")
+		fs.Usage()
+		return 2
+	}
+
+	names := strings.Split(*types, ",")
+	if *output == "" {
+		*output = strings.ToLower(names[0]) + "_env.go"
+	}
+
+	src, err := generate(dir, names, *prefix, *sep, *style)
+	if err != nil {
+		fmt.Fprintf(stderr, "envgen: %v\n", err)
+		return 1
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, *output), src, 0o644); err != nil {
+		fmt.Fprintf(stderr, "envgen: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// envPath is the import path of the env package.
+const envPath = "github.com/junk1tm/env"
+
+// expansionStyles maps the values of the -expansion-style flag to the options
+// passed to [env.ExpandValue].
+var expansionStyles = map[string]string{
+	"unix":    "",
+	"windows": ", env.WithExpansionStyle(env.ExpandWindows)",
+	"both":    ", env.WithExpansionStyle(env.ExpandBoth)",
+}
+
+// generate parses the Go package in dir and returns the formatted source code
+// of the loaders for the struct types named by names.
+func generate(dir string, names []string, prefix, sep, style string) ([]byte, error) {
+	expandOpts, ok := expansionStyles[style]
+	if !ok {
+		return nil, fmt.Errorf("invalid expansion style %q", style)
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a single package in %s, got %d", dir, len(pkgs))
+	}
+
+	g := generator{
+		buf:        new(bytes.Buffer),
+		structs:    make(map[string]*ast.StructType),
+		imports:    make(map[string]bool),
+		prefix:     prefix,
+		sep:        sep,
+		expandOpts: expandOpts,
+	}
+
+	var pkgName string
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if ts, ok := n.(*ast.TypeSpec); ok {
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						g.structs[ts.Name.Name] = st
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	for _, name := range names {
+		st, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found", name)
+		}
+		if err := g.genLoader(name, st); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by envgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	fmt.Fprintf(&buf, "\n\t%q\n)\n", envPath)
+	buf.Write(g.buf.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// generator generates the loaders.
+type generator struct {
+	buf        *bytes.Buffer
+	structs    map[string]*ast.StructType // the struct types declared in the package.
+	imports    map[string]bool            // the packages used by the generated code.
+	prefix     string
+	sep        string
+	expandOpts string // the options passed to env.ExpandValue, see expansionStyles.
+}
+
+// genLoader generates the loader for the struct type named by name.
+func (g *generator) genLoader(name string, st *ast.StructType) error {
+	fmt.Fprintf(g.buf, "\n// Load%s loads environment variables into a new %s using the specified\n", name, name)
+	fmt.Fprintf(g.buf, "// Provider as their source. If p is nil, the default Provider is used.\n")
+	fmt.Fprintf(g.buf, "func Load%s(p env.Provider) (%s, error) {\n", name, name)
+	fmt.Fprintf(g.buf, "var cfg %s\nif p == nil {\np = env.DefaultProvider()\n}\n", name)
+	g.buf.WriteString("var notset []string\n")
+
+	// generate the fields first to know whether the lookup variables are used.
+	out := g.buf
+	g.buf = new(bytes.Buffer)
+	if err := g.genStruct("cfg", st); err != nil {
+		return err
+	}
+	fields := g.buf
+	g.buf = out
+	if fields.Len() > 0 {
+		g.buf.WriteString("var (\nv string\nok bool\n)\n")
+	}
+	g.buf.Write(fields.Bytes())
+
+	g.buf.WriteString("\nif len(notset) > 0 {\nreturn cfg, &env.NotSetError{Names: notset}\n}\n")
+	g.buf.WriteString("return cfg, nil\n}\n")
+	return nil
+}
+
+// genStruct generates the code loading the fields of st. path is the
+// expression referring to the struct value.
+func (g *generator) genStruct(path string, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		names := field.Names
+		if len(names) == 0 {
+			// an embedded field, its name is the name of the type.
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				// env.LoadFrom would parse the fields of the embedded struct,
+				// but its declaration is not available here.
+				return fmt.Errorf("unsupported embedded field %s", exprString(field.Type))
+			}
+			names = []*ast.Ident{ident}
+		}
+
+		for _, name := range names {
+			if !name.IsExported() {
+				// skip unexported fields.
+				continue
+			}
+			if err := g.genField(path+"."+name.Name, field); err != nil {
+				return fmt.Errorf("field %s: %w", name.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// genField generates the code loading a single field. path is the expression
+// referring to the field value.
+func (g *generator) genField(path string, field *ast.Field) error {
+	// special case: a nested struct, generate the code for its fields.
+	switch typ := field.Type.(type) {
+	case *ast.StructType:
+		return g.genStruct(path, typ)
+	case *ast.Ident:
+		if st, ok := g.structs[typ.Name]; ok {
+			return g.genStruct(path, st)
+		}
+	}
+
+	if field.Tag == nil {
+		return nil
+	}
+	rawTag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return err
+	}
+	tag := reflect.StructTag(rawTag)
+
+	value, ok := tag.Lookup("env")
+	if !ok {
+		// skip fields without the `env` tag.
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	name, options := parts[0], parts[1:]
+	if name == "" {
+		return errors.New("empty tag name is not allowed")
+	}
+
+	var required, expand bool
+	for _, option := range options {
+		switch option {
+		case "required":
+			required = true
+		case "expand":
+			expand = true
+		case "secret":
+			// secrets are loaded the same way.
+		default:
+			return fmt.Errorf("invalid tag option %q", option)
+		}
+	}
+
+	kind, slice, err := fieldKind(field.Type)
+	if err != nil {
+		return err
+	}
+
+	name = g.prefix + name
+	fmt.Fprintf(g.buf, "\n// %s\nv, ok = p.LookupEnv(%q)\n", path, name)
+	if expand {
+		g.genExpand(name)
+	}
+	if names, ok := tag.Lookup("deprecated"); ok {
+		for _, old := range strings.Split(names, ",") {
+			fmt.Fprintf(g.buf, "if !ok {\nv, ok = p.LookupEnv(%q)\n", g.prefix+old)
+			if expand {
+				g.genExpand(g.prefix + old)
+			}
+			g.buf.WriteString("}\n")
+		}
+	}
+
+	def, hasDefault := tag.Lookup("default")
+	switch {
+	case required:
+		fmt.Fprintf(g.buf, "if !ok {\nnotset = append(notset, %q)\n}\n", name)
+	case hasDefault:
+		fmt.Fprintf(g.buf, "if !ok {\nv, ok = %q, true\n}\n", def)
+	}
+
+	g.buf.WriteString("if ok {\n")
+	if slice {
+		g.imports["strings"] = true
+		fmt.Fprintf(g.buf, "%s = nil\nfor _, s := range strings.Split(v, %q) {\n", path, g.sep)
+		g.genParse(kind, name, "s", path+" = append("+path+", %s)")
+		g.buf.WriteString("}\n")
+	} else {
+		g.genParse(kind, name, "v", path+" = %s")
+	}
+	g.buf.WriteString("}\n")
+
+	return nil
+}
+
+// genExpand generates the code expanding the value of the environment variable
+// named by name, if it is set.
+func (g *generator) genExpand(name string) {
+	fmt.Fprintf(g.buf, "if ok {\ns, err := env.ExpandValue(p, %q, v%s)\nif err != nil {\nreturn cfg, err\n}\nv = s\n}\n", name, g.expandOpts)
+}
+
+// genParse generates the code parsing src as a value of the provided kind and
+// passing the result to assign, which is a format string with a single %s verb.
+// The parsing errors are reported as env.ParseError for the variable named by
+// name.
+func (g *generator) genParse(kind, name, src, assign string) {
+	var parse, errPrefix string
+	switch kind {
+	case "string":
+		fmt.Fprintf(g.buf, assign+"\n", src)
+		return
+	case "bool":
+		parse, errPrefix = fmt.Sprintf("strconv.ParseBool(%s)", src), "parsing bool"
+	case "int", "int8", "int16", "int32", "int64":
+		parse, errPrefix = fmt.Sprintf("strconv.ParseInt(%s, 10, %d)", src, bitSize(kind)), "parsing int"
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		parse, errPrefix = fmt.Sprintf("strconv.ParseUint(%s, 10, %d)", src, bitSize(kind)), "parsing uint"
+	case "float32", "float64":
+		parse, errPrefix = fmt.Sprintf("strconv.ParseFloat(%s, %d)", src, bitSize(kind)), "parsing float"
+	case "time.Duration":
+		parse, errPrefix = fmt.Sprintf("time.ParseDuration(%s)", src), "parsing duration"
+	}
+
+	if kind == "time.Duration" {
+		g.imports["time"] = true
+	} else {
+		g.imports["strconv"] = true
+	}
+	g.imports["fmt"] = true
+
+	fmt.Fprintf(g.buf, "x, err := %s\nif err != nil {\nreturn cfg, &env.ParseError{Name: %q, Err: fmt.Errorf(\"%s: %%w\", err)}\n}\n", parse, name, errPrefix)
+
+	value := "x"
+	switch kind {
+	case "bool", "int64", "uint64", "float64", "time.Duration":
+	default:
+		value = kind + "(x)"
+	}
+	fmt.Fprintf(g.buf, assign+"\n", value)
+}
+
+// fieldKind returns the name of the type of a field (or of its elements, if the
+// field is a slice) and reports whether the field is a slice.
+func fieldKind(expr ast.Expr) (kind string, slice bool, err error) {
+	if at, ok := expr.(*ast.ArrayType); ok && at.Len == nil {
+		kind, _, err := fieldKind(at.Elt)
+		return kind, true, err
+	}
+
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		if bitSize(typ.Name) != -1 || typ.Name == "string" || typ.Name == "bool" {
+			return typ.Name, false, nil
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := typ.X.(*ast.Ident); ok && pkg.Name == "time" && typ.Sel.Name == "Duration" {
+			return "time.Duration", false, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("unsupported type %s", exprString(expr))
+}
+
+// bitSize returns the bit size of a numeric kind (0 for int and uint), or -1,
+// if the kind is not numeric.
+func bitSize(kind string) int {
+	switch kind {
+	case "int", "uint":
+		return 0
+	case "int8", "uint8":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	case "int64", "uint64", "float64":
+		return 64
+	default:
+		return -1
+	}
+}
+
+// exprString formats expr as Go source code.
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return fmt.Sprintf("%T", expr)
+	}
+	return buf.String()
+}
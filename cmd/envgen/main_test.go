@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestGenerate(t *testing.T) {
+	got, err := generate("testdata", []string{"Config"}, "APP_", " ", "unix")
+	assert.NoErr[F](t, err)
+
+	want, err := os.ReadFile(filepath.Join("testdata", "config_env.go.golden"))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, string(got), string(want))
+}
+
+// equivalenceMain compares the loader generated from testdata/config.go with
+// env.LoadFrom on the same inputs.
+const equivalenceMain = `package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/junk1tm/env"
+
+	"envgentest/testdata"
+)
+
+func main() {
+	inputs := []env.Map{
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret"},
+		{"APP_DATABASE_HOST": "localhost", "APP_DB_PORT": "05432", "APP_TOKEN": "secret", "APP_DEBUG": "1"},
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret", "APP_PORTS": "8080 8443", "APP_RATIO": "0.5", "APP_TIMEOUT": "1m"},
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret", "APP_CACHE_URL": "redis://$ADDR", "ADDR": "${HOST}:6379", "HOST": "cache"},
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret", "APP_HTTP_PROXY": "http://$A", "A": "$B", "B": "$A"},
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret", "APP_DB_PORT": "x"},
+		{"APP_DB_HOST": "localhost", "APP_TOKEN": "secret", "APP_PORTS": "80 70000"},
+		{"APP_DEBUG": "yes"},
+		{},
+	}
+
+	code := 0
+	for _, m := range inputs {
+		got, gotErr := testdata.LoadConfig(m)
+		var want testdata.Config
+		wantErr := env.LoadFrom(m, &want, env.WithPrefix("APP_"))
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) || reflect.TypeOf(gotErr) != reflect.TypeOf(wantErr) {
+			fmt.Printf("%v: got error %#v; want %#v\n", m, gotErr, wantErr)
+			code = 1
+		} else if wantErr == nil && !reflect.DeepEqual(got, want) {
+			fmt.Printf("%v: got %+v; want %+v\n", m, got, want)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+`
+
+// TestGeneratedCode compiles the golden output and checks that it loads the
+// same values and reports the same errors as env.LoadFrom.
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode: builds a program")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is not available")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.NoErr[F](t, err)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module envgentest\n\ngo 1.19\n\nrequire github.com/junk1tm/env v0.0.0\n\nreplace github.com/junk1tm/env => " + root + "\n",
+		"main.go": equivalenceMain,
+	}
+	for _, name := range []string{"config.go", "config_env.go.golden"} {
+		src, err := os.ReadFile(filepath.Join("testdata", name))
+		assert.NoErr[F](t, err)
+		files[filepath.Join("testdata", strings.TrimSuffix(name, ".golden"))] = string(src)
+	}
+	for name, src := range files {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755)
+		assert.NoErr[F](t, err)
+		err = os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644)
+		assert.NoErr[F](t, err)
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code differs from env.LoadFrom: %v\n%s", err, out)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("output", func(t *testing.T) {
+		dir := t.TempDir()
+		src, err := os.ReadFile(filepath.Join("testdata", "config.go"))
+		assert.NoErr[F](t, err)
+		err = os.WriteFile(filepath.Join(dir, "config.go"), src, 0o644)
+		assert.NoErr[F](t, err)
+
+		var stderr bytes.Buffer
+		code := run([]string{"-type", "Config,Database"}, dir, &stderr)
+		assert.Equal[F](t, code, 0)
+
+		got, err := os.ReadFile(filepath.Join(dir, "config_env.go"))
+		assert.NoErr[F](t, err)
+		assert.Contains[E](t, string(got), "func LoadConfig(p env.Provider) (Config, error)")
+		assert.Contains[E](t, string(got), "func LoadDatabase(p env.Provider) (Database, error)")
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "package config\n\nimport \"net\"\n\ntype Config struct {\n\tIP net.IP `env:\"IP\"`\n}\n"
+		err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o644)
+		assert.NoErr[F](t, err)
+
+		var stderr bytes.Buffer
+		code := run([]string{"-type", "Config"}, dir, &stderr)
+		assert.Equal[E](t, code, 1)
+		assert.Equal[E](t, stderr.String(), "envgen: Config: field IP: unsupported type net.IP\n")
+	})
+
+	t.Run("unsupported embedded field", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "package config\n\nimport \"example.com/db\"\n\ntype Config struct {\n\tdb.Config\n}\n"
+		err := os.WriteFile(filepath.Join(dir, "config.go"), []byte(src), 0o644)
+		assert.NoErr[F](t, err)
+
+		var stderr bytes.Buffer
+		code := run([]string{"-type", "Config"}, dir, &stderr)
+		assert.Equal[E](t, code, 1)
+		assert.Equal[E](t, stderr.String(), "envgen: Config: unsupported embedded field db.Config\n")
+	})
+
+	t.Run("invalid expansion style", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := run([]string{"-type", "Config", "-expansion-style", "dos"}, "testdata", &stderr)
+		assert.Equal[E](t, code, 1)
+		assert.Equal[E](t, stderr.String(), "envgen: invalid expansion style \"dos\"\n")
+	})
+
+	t.Run("no type", func(t *testing.T) {
+		code := run(nil, t.TempDir(), new(bytes.Buffer))
+		assert.Equal[E](t, code, 2)
+	})
+}
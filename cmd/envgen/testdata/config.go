@@ -0,0 +1,23 @@
+package testdata
+
+import "time"
+
+type Config struct {
+	DB    Database
+	Cache struct {
+		URL string `env:"CACHE_URL,expand" default:"redis://${HOST}"`
+	}
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Ports   []uint16      `env:"PORTS" default:"80 443"`
+	Ratio   float32       `env:"RATIO"`
+	Debug   bool          `env:"DEBUG"`
+	Token   string        `env:"TOKEN,required,secret"`
+	Proxy   string        `env:"PROXY,expand" deprecated:"HTTP_PROXY"`
+	ignored int
+}
+
+type Database struct {
+	Host string `env:"DB_HOST,required" deprecated:"DATABASE_HOST"`
+	Port int    `env:"DB_PORT" default:"5432"`
+	Name string
+}
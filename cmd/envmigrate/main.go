@@ -0,0 +1,387 @@
+// Command envmigrate rewrites the struct tags of other environment variables
+// libraries into the format of this package, so that large migrations can be
+// mostly automated:
+//
+//	envmigrate -from envconfig [-w] file.go...
+//
+// The following libraries are supported:
+//
+//   - envconfig: github.com/kelseyhightower/envconfig
+//   - caarlos0: github.com/caarlos0/env
+//
+// By default, the rewritten files are printed to stdout; use the -w flag to
+// overwrite them instead. The features that cannot be migrated automatically
+// are reported to stderr, one per line, prefixed with the position of the
+// field, and the corresponding tags (or tag options) are left intact to be
+// migrated manually. Since envconfig prefixes the names of the fields of
+// nested structs, the envconfig fields of any types other than the basic ones,
+// time.Duration, time.Time, net.IP and slices of them are reported as well.
+// Note that envconfig also loads the fields without any
+// tags, deriving the names from the names of the fields; such fields are not
+// rewritten, since they cannot be told apart from the fields of unrelated
+// structs.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envmigrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "the library to migrate from: envconfig or caarlos0 (required)")
+	write := fs.Bool("w", false, "write the result to the source files instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var rewrite func(*migrator, *ast.Field, []tagPair) []tagPair
+	switch *from {
+	case "envconfig":
+		rewrite = (*migrator).envconfig
+	case "caarlos0":
+		rewrite = (*migrator).caarlos0
+	default:
+		fmt.Fprintln(stderr, "envmigrate: the -from flag must be either envconfig or caarlos0")
+		fs.Usage()
+		return 2
+	}
+
+	for _, name := range fs.Args() {
+		m := migrator{fset: token.NewFileSet(), rewrite: rewrite, reports: stderr}
+		src, err := m.migrate(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "envmigrate: %v\n", err)
+			return 1
+		}
+
+		if *write {
+			err = os.WriteFile(name, src, 0o644)
+		} else {
+			_, err = stdout.Write(src)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "envmigrate: %v\n", err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// migrator rewrites the struct tags of a single file.
+type migrator struct {
+	fset    *token.FileSet
+	rewrite func(*migrator, *ast.Field, []tagPair) []tagPair
+	reports io.Writer
+	field   *ast.Field // the field being rewritten.
+}
+
+// migrate rewrites the struct tags of the file named by name and returns the
+// formatted result.
+func (m *migrator) migrate(name string) ([]byte, error) {
+	file, err := parser.ParseFile(m.fset, name, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagErr error
+	ast.Inspect(file, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok || tagErr != nil {
+			return tagErr == nil
+		}
+
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			m.field = field
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				tagErr = err
+				return false
+			}
+			pairs, err := parseTag(tag)
+			if err != nil {
+				tagErr = fmt.Errorf("%s: %w", m.fset.Position(field.Pos()), err)
+				return false
+			}
+			if rewritten := m.rewrite(m, field, pairs); rewritten != nil {
+				field.Tag.Value = formatTag(rewritten)
+			}
+		}
+		return true
+	})
+	if tagErr != nil {
+		return nil, tagErr
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, m.fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// report reports a feature of the current field that cannot be migrated.
+func (m *migrator) report(format string, args ...any) {
+	name := "embedded field"
+	if len(m.field.Names) > 0 {
+		name = "field " + m.field.Names[0].Name
+	}
+	fmt.Fprintf(m.reports, "%s: %s: %s\n", m.fset.Position(m.field.Pos()), name, fmt.Sprintf(format, args...))
+}
+
+// envconfig rewrites the tags of github.com/kelseyhightower/envconfig. It
+// returns nil, if the tags of the field are not changed.
+func (m *migrator) envconfig(field *ast.Field, pairs []tagPair) []tagPair {
+	var (
+		name, def            string
+		hasName, hasDef      bool
+		required, splitWords bool
+		ignored, relevant    bool
+		migrated             bool
+		rest                 []tagPair
+	)
+	for _, p := range pairs {
+		switch p.key {
+		case "env":
+			migrated = true
+			rest = append(rest, p)
+		case "envconfig":
+			name, hasName, relevant = p.value, true, true
+		case "default":
+			def, hasDef, relevant = p.value, true, true
+		case "required":
+			required, relevant = p.value == "true", true
+		case "split_words":
+			splitWords, relevant = p.value == "true", true
+		case "ignored":
+			ignored, relevant = p.value == "true", true
+		default:
+			rest = append(rest, p)
+		}
+	}
+	if !relevant || migrated {
+		// the field is already migrated, e.g. its `default` tag belongs to
+		// this package.
+		return nil
+	}
+	if ignored {
+		return rest
+	}
+
+	if !hasName {
+		if len(field.Names) == 0 {
+			m.report("the name of an embedded field cannot be derived")
+			return nil
+		}
+		name = strings.ToUpper(field.Names[0].Name)
+		if splitWords {
+			name = splitWordsName(field.Names[0].Name)
+		}
+	}
+
+	if _, ok := field.Type.(*ast.StructType); ok {
+		m.report("envconfig prefixes the names of the nested fields, which is not supported")
+		return nil
+	}
+	if !isKnownType(field.Type) {
+		// a named type may be a struct declared elsewhere, whose fields
+		// envconfig would prefix.
+		m.report("the type %s may be a nested struct; envconfig prefixes the names of the nested fields, which is not supported", types.ExprString(field.Type))
+		return nil
+	}
+
+	value := name
+	if required {
+		value += ",required"
+	}
+
+	out := []tagPair{{key: "env", value: value}}
+	if hasDef {
+		out = append(out, tagPair{key: "default", value: def})
+	}
+	return append(out, rest...)
+}
+
+// caarlos0 rewrites the tags of github.com/caarlos0/env. It returns nil, if the
+// tags of the field are not changed.
+func (m *migrator) caarlos0(_ *ast.Field, pairs []tagPair) []tagPair {
+	var (
+		changed bool
+		out     []tagPair
+	)
+	for _, p := range pairs {
+		switch p.key {
+		case "env":
+			parts := strings.Split(p.value, ",")
+			if parts[0] == "" {
+				m.report("the name of the variable is required")
+			}
+			for _, option := range parts[1:] {
+				switch option {
+				case "required", "expand":
+				case "notEmpty", "unset", "file", "init":
+					m.report("the %q option is not supported", option)
+				default:
+					m.report("unknown option %q", option)
+				}
+			}
+			out = append(out, p)
+		case "envDefault":
+			out = append(out, tagPair{key: "default", value: p.value})
+			changed = true
+		case "envExpand":
+			if p.value == "true" {
+				out = appendOption(out, "expand")
+			}
+			changed = true
+		case "envSeparator", "envKeyValSeparator":
+			m.report("the %q tag is not supported, use the WithSliceSeparator option instead", p.key)
+			out = append(out, p)
+		case "envPrefix":
+			m.report("the %q tag is not supported, use the WithPrefix option instead", p.key)
+			out = append(out, p)
+		default:
+			out = append(out, p)
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return out
+}
+
+// appendOption appends a tag-level option to the `env` tag in pairs.
+func appendOption(pairs []tagPair, option string) []tagPair {
+	for i, p := range pairs {
+		if p.key == "env" {
+			pairs[i].value += "," + option
+			return pairs
+		}
+	}
+	return pairs
+}
+
+// knownTypes are the types of the fields that can be migrated automatically,
+// i.e. those that are known not to be structs.
+var knownTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+	"time.Duration": true, "time.Time": true, "net.IP": true,
+}
+
+// isKnownType reports whether expr is one of the known types or a slice of
+// them. The package qualifiers are matched as written, so aliased imports are
+// not recognized.
+func isKnownType(expr ast.Expr) bool {
+	if at, ok := expr.(*ast.ArrayType); ok && at.Len == nil {
+		return isKnownType(at.Elt)
+	}
+	switch expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return knownTypes[types.ExprString(expr)]
+	}
+	return false
+}
+
+var (
+	gatherRegexp  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// splitWordsName returns the name of the variable derived from the name of the
+// field the same way envconfig does it for the `split_words` tag, e.g.
+// "MaxIdleConns" becomes "MAX_IDLE_CONNS".
+func splitWordsName(field string) string {
+	var words []string
+	for _, match := range gatherRegexp.FindAllStringSubmatch(field, -1) {
+		if m := acronymRegexp.FindStringSubmatch(match[0]); len(m) == 3 {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, match[0])
+		}
+	}
+	return strings.ToUpper(strings.Join(words, "_"))
+}
+
+// tagPair is a single key:"value" pair of a struct tag.
+type tagPair struct {
+	key   string
+	value string
+}
+
+// parseTag parses a struct tag into key:"value" pairs, preserving their order.
+// The syntax is the same one [reflect.StructTag.Get] expects.
+func parseTag(tag string) ([]tagPair, error) {
+	var pairs []tagPair
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+
+		i := strings.Index(tag, `:"`)
+		if i <= 0 || strings.ContainsAny(tag[:i], " \"") {
+			return nil, fmt.Errorf("malformed struct tag %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan to the closing quote, skipping escaped characters.
+		j := 1
+		for j < len(tag) && tag[j] != '"' {
+			if tag[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(tag) {
+			return nil, fmt.Errorf("malformed struct tag %q", tag)
+		}
+
+		value, err := strconv.Unquote(tag[:j+1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed struct tag %q", tag)
+		}
+		pairs = append(pairs, tagPair{key: key, value: value})
+		tag = tag[j+1:]
+	}
+	return pairs, nil
+}
+
+// formatTag formats pairs as a raw string literal of a struct tag.
+func formatTag(pairs []tagPair) string {
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.key + ":" + strconv.Quote(p.value)
+	}
+
+	tag := strings.Join(parts, " ")
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
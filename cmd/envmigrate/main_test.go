@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRun(t *testing.T) {
+	test := func(from, reports string) {
+		t.Run(from, func(t *testing.T) {
+			name := filepath.Join("testdata", from+".go")
+			want, err := os.ReadFile(name + ".golden")
+			assert.NoErr[F](t, err)
+
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"-from", from, name}, &stdout, &stderr)
+			assert.Equal[F](t, code, 0)
+			assert.Equal[E](t, stdout.String(), string(want))
+			assert.Equal[E](t, stderr.String(), reports)
+		})
+	}
+
+	test("envconfig", "testdata/envconfig.go:11:2: field DB: envconfig prefixes the names of the nested fields, which is not supported\n"+
+		"testdata/envconfig.go:14:2: field Cache: the type CacheConfig may be a nested struct; envconfig prefixes the names of the nested fields, which is not supported\n")
+	test("caarlos0", "testdata/caarlos0.go:9:2: field Timeout: the \"notEmpty\" option is not supported\n"+
+		"testdata/caarlos0.go:10:2: field Hosts: the \"envSeparator\" tag is not supported, use the WithSliceSeparator option instead\n"+
+		"testdata/caarlos0.go:11:2: field Key: the \"file\" option is not supported\n"+
+		"testdata/caarlos0.go:12:2: field DB: the \"envPrefix\" tag is not supported, use the WithPrefix option instead\n")
+
+	t.Run("write", func(t *testing.T) {
+		src, err := os.ReadFile(filepath.Join("testdata", "caarlos0.go"))
+		assert.NoErr[F](t, err)
+
+		name := filepath.Join(t.TempDir(), "config.go")
+		err = os.WriteFile(name, src, 0o644)
+		assert.NoErr[F](t, err)
+
+		code := run([]string{"-from", "caarlos0", "-w", name}, new(bytes.Buffer), new(bytes.Buffer))
+		assert.Equal[F](t, code, 0)
+
+		got, err := os.ReadFile(name)
+		assert.NoErr[F](t, err)
+		want, err := os.ReadFile(filepath.Join("testdata", "caarlos0.go.golden"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, string(got), string(want))
+	})
+
+	t.Run("unknown library", func(t *testing.T) {
+		code := run([]string{"-from", "viper"}, new(bytes.Buffer), new(bytes.Buffer))
+		assert.Equal[E](t, code, 2)
+	})
+}
+
+func TestSplitWordsName(t *testing.T) {
+	test := func(field, name string) {
+		t.Run(field, func(t *testing.T) {
+			assert.Equal[E](t, splitWordsName(field), name)
+		})
+	}
+
+	test("Port", "PORT")
+	test("MaxIdleConns", "MAX_IDLE_CONNS")
+	test("APIKey", "API_KEY")
+	test("HTTPServerURL", "HTTP_SERVER_URL")
+}
+
+func TestParseTag(t *testing.T) {
+	pairs, err := parseTag(`env:"HOST,required" desc:"a \"quoted\" value"`)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, pairs, []tagPair{
+		{key: "env", value: "HOST,required"},
+		{key: "desc", value: `a "quoted" value`},
+	})
+
+	_, err = parseTag(`env:"HOST`)
+	assert.ErrorContains[E](t, err, "malformed struct tag")
+}
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+type Config struct {
+	Host    string        `env:"HOST,required"`
+	Port    int           `env:"PORT" envDefault:"8080" json:"port"`
+	URL     string        `env:"URL" envDefault:"http://${HOST}" envExpand:"true"`
+	Timeout time.Duration `env:"TIMEOUT,notEmpty" envDefault:"5s"`
+	Hosts   []string      `env:"HOSTS" envSeparator:","`
+	Key     string        `env:"KEY,file"`
+	DB      struct {
+		Name string `env:"NAME"`
+	} `envPrefix:"DB_"`
+}
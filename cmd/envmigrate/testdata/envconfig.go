@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type Config struct {
+	Host         string `envconfig:"HOST" required:"true"`
+	Port         int    `default:"8080" json:"port"`
+	MaxIdleConns int    `split_words:"true" default:"10"`
+	APIKey       string `split_words:"true" desc:"API key"`
+	Internal     string `ignored:"true" json:"-"`
+	DB           struct {
+		Name string `envconfig:"DB_NAME"`
+	} `split_words:"true"`
+	Cache   CacheConfig   `envconfig:"CACHE"`
+	Timeout time.Duration `default:"5s"`
+	Hosts   []string      `envconfig:"HOSTS"`
+	Plain   string        `json:"plain"`
+	Debug   bool          `env:"DEBUG" default:"true"`
+}
+
+type CacheConfig struct {
+	Size int `envconfig:"SIZE"`
+}
@@ -0,0 +1,199 @@
+// Command envstruct generates a Go config struct from an existing dotenv file,
+// giving brownfield projects a fast starting point:
+//
+//	envstruct [-type Config] [-package config] .env > config.go
+//
+// Each variable becomes a field named after it, e.g. DB_HOST becomes DBHost.
+// The types are inferred from the values: bool, int, float64, [time.Duration],
+// and string otherwise. The values are used as the defaults, except for the
+// variables that look like secrets (i.e. their names contain PASSWORD, SECRET,
+// TOKEN, or KEY): they are marked as required and secret instead, so that their
+// values never end up in the code. The variables with empty values are marked
+// as required. The fields are sorted by the names of the variables.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/junk1tm/env"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envstruct", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typ := fs.String("type", "Config", "the name of the struct type")
+	pkg := fs.String("package", "config", "the name of the package")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "envstruct: a single dotenv file is required")
+		fs.Usage()
+		return 2
+	}
+
+	m, err := env.ReadDotenv(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "envstruct: %v\n", err)
+		return 1
+	}
+
+	src, err := generate(m, *pkg, *typ)
+	if err != nil {
+		fmt.Fprintf(stderr, "envstruct: %v\n", err)
+		return 1
+	}
+
+	if _, err := stdout.Write(src); err != nil {
+		fmt.Fprintf(stderr, "envstruct: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// generate returns the formatted source code of a struct type named by typ
+// declared in the package named by pkg, with a field per variable in m.
+func generate(m env.Map, pkg, typ string) ([]byte, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		fields     bytes.Buffer
+		importTime bool
+		seen       = make(map[string]int)
+	)
+	for _, name := range names {
+		value := m[name]
+
+		field := fieldName(name)
+		if seen[field]++; seen[field] > 1 {
+			// e.g. DB_HOST and DBHOST.
+			field += strconv.Itoa(seen[field])
+		}
+
+		fieldType := inferType(value)
+		if fieldType == "time.Duration" {
+			importTime = true
+		}
+
+		tag := "env:" + strconv.Quote(name)
+		switch {
+		case isSecret(name):
+			tag = "env:" + strconv.Quote(name+",required,secret")
+		case value == "":
+			tag = "env:" + strconv.Quote(name+",required")
+		default:
+			tag += " default:" + strconv.Quote(value)
+		}
+
+		fmt.Fprintf(&fields, "\t%s %s %s\n", field, fieldType, quoteTag(tag))
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	if importTime {
+		buf.WriteString("import \"time\"\n\n")
+	}
+	fmt.Fprintf(&buf, "type %s struct {\n%s}\n", typ, fields.String())
+
+	return format.Source(buf.Bytes())
+}
+
+// commonInitialisms is a set of words that are written in uppercase in Go
+// identifiers.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "LHS": true, "QPS": true, "RAM": true,
+	"RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// fieldName converts the name of a variable to a Go field name, e.g. DB_HOST
+// becomes DBHost.
+func fieldName(name string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		sb.WriteString(upper[:1] + strings.ToLower(word[1:]))
+	}
+
+	field := sb.String()
+	if field == "" || '0' <= field[0] && field[0] <= '9' {
+		// not a valid identifier.
+		field = "Var" + field
+	}
+	return field
+}
+
+// inferType returns the name of the Go type inferred from the value.
+func inferType(value string) string {
+	if value == "" {
+		return "string"
+	}
+	if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return "bool"
+	}
+	if _, err := strconv.ParseInt(value, 10, 0); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && isNumber(value) {
+		return "float64"
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return "time.Duration"
+	}
+	return "string"
+}
+
+// isNumber reports whether value consists of digits, with an optional sign and
+// decimal point, so that values like "NaN" or "Inf" are not treated as float.
+func isNumber(value string) bool {
+	value = strings.TrimLeft(value, "+-")
+	return value != "" && strings.Trim(value, "0123456789.") == ""
+}
+
+// isSecret reports whether the name of a variable looks like a secret.
+func isSecret(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range []string{"PASSWORD", "SECRET", "TOKEN", "KEY"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteTag formats tag as a raw string literal, if possible.
+func quoteTag(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
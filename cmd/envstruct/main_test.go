@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("generate", func(t *testing.T) {
+		want, err := os.ReadFile(filepath.Join("testdata", "config.go.golden"))
+		assert.NoErr[F](t, err)
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{filepath.Join("testdata", ".env")}, &stdout, &stderr)
+		assert.Equal[F](t, code, 0)
+		assert.Equal[E](t, stdout.String(), string(want))
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		code := run(nil, new(bytes.Buffer), new(bytes.Buffer))
+		assert.Equal[E](t, code, 2)
+	})
+}
+
+func TestFieldName(t *testing.T) {
+	test := func(name, field string) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal[E](t, fieldName(name), field)
+		})
+	}
+
+	test("PORT", "Port")
+	test("DB_HOST", "DBHost")
+	test("HTTP_PROXY_URL", "HTTPProxyURL")
+	test("max_idle_conns", "MaxIdleConns")
+	test("2FA_ENABLED", "Var2faEnabled")
+}
+
+func TestInferType(t *testing.T) {
+	test := func(value, typ string) {
+		t.Run(value, func(t *testing.T) {
+			assert.Equal[E](t, inferType(value), typ)
+		})
+	}
+
+	test("", "string")
+	test("true", "bool")
+	test("1", "int")
+	test("-1.5", "float64")
+	test("NaN", "string")
+	test("1h30m", "time.Duration")
+	test("localhost", "string")
+}
@@ -0,0 +1,71 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// redacted is a placeholder used instead of the values of secret variables.
+const redacted = "***"
+
+// FieldChange describes a single field that differs between two configs. It is
+// reported by the [Diff] function.
+type FieldChange struct {
+	Field string // Field is the path of the struct field, e.g. "DB.Port".
+	Name  string // Name is the name of the corresponding environment variable.
+	Old   string // Old is the old value. If the variable is marked as secret, it will be redacted.
+	New   string // New is the new value. If the variable is marked as secret, it will be redacted.
+}
+
+// Diff compares two configs of the same type and reports the fields that have
+// changed, in the order of their declaration. Both before and after must be
+// non-nil struct pointers of the same type, otherwise Diff returns
+// [ErrInvalidArgument]. Only the fields with the `env` tag are compared. The
+// values of the variables marked as secret are redacted.
+func Diff(before, after any) ([]FieldChange, error) {
+	rb, ra := reflect.ValueOf(before), reflect.ValueOf(after)
+	if !structPtr(rb) || !structPtr(ra) || rb.Type() != ra.Type() {
+		return nil, ErrInvalidArgument
+	}
+
+	l := newLoader(nil)
+
+	oldVars, err := l.parseVars(rb.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	newVars, err := l.parseVars(ra.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+
+	// both configs have the same type, so the vars are guaranteed to be in the
+	// same order.
+	for i := range oldVars {
+		ov, nv := oldVars[i], newVars[i]
+		if ov.opaque() || reflect.DeepEqual(ov.field.Interface(), nv.field.Interface()) {
+			continue
+		}
+
+		changes = append(changes, newFieldChange(nv, ov.field, nv.field))
+	}
+
+	return changes, nil
+}
+
+// newFieldChange creates a new [FieldChange] of v from old to new, redacting
+// the values if v is marked as secret.
+func newFieldChange(v Var, oldValue, newValue reflect.Value) FieldChange {
+	if v.Secret {
+		return FieldChange{Field: v.Field, Name: v.Name, Old: redacted, New: redacted}
+	}
+	return FieldChange{
+		Field: v.Field,
+		Name:  v.Name,
+		Old:   fmt.Sprintf("%v", oldValue.Interface()),
+		New:   fmt.Sprintf("%v", newValue.Interface()),
+	}
+}
@@ -0,0 +1,55 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestDiff(t *testing.T) {
+	type config struct {
+		DB struct {
+			Host     string `env:"DB_HOST"`
+			Port     int    `env:"DB_PORT"`
+			Password string `env:"DB_PASSWORD,secret"`
+		}
+		Ignored string
+	}
+
+	t.Run("invalid argument", func(t *testing.T) {
+		test := func(name string, before, after any) {
+			t.Run(name, func(t *testing.T) {
+				_, err := env.Diff(before, after)
+				assert.IsErr[E](t, err, env.ErrInvalidArgument)
+			})
+		}
+
+		test("nil", nil, nil)
+		test("not a struct pointer", new(int), new(int))
+		test("different types", new(config), new(struct{}))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		var before, after config
+		changes, err := env.Diff(&before, &after)
+		assert.NoErr[F](t, err)
+		assert.Len[E](t, changes, 0)
+	})
+
+	t.Run("changed fields", func(t *testing.T) {
+		var before, after config
+		before.DB.Host, after.DB.Host = "localhost", "localhost"
+		before.DB.Port, after.DB.Port = 5432, 5433
+		before.DB.Password, after.DB.Password = "foo", "bar"
+		before.Ignored, after.Ignored = "foo", "bar"
+
+		changes, err := env.Diff(&before, &after)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, changes, []env.FieldChange{
+			{Field: "DB.Port", Name: "DB_PORT", Old: "5432", New: "5433"},
+			{Field: "DB.Password", Name: "DB_PASSWORD", Old: "***", New: "***"},
+		})
+	})
+}
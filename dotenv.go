@@ -0,0 +1,86 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseDotenv parses environment variables in the dotenv format from r. The
+// following syntax is supported:
+//
+//	# comments and empty lines are ignored
+//	KEY=value
+//	export KEY=value
+//	KEY="double-quoted value, escape sequences like \n are interpreted"
+//	KEY='single-quoted value, taken literally'
+//
+// Unquoted values are trimmed of surrounding whitespace. If a key is specified
+// more than once, the last value wins.
+func ParseDotenv(r io.Reader) (Map, error) {
+	m := make(Map)
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("env: parsing dotenv: line %d: %q is not in the KEY=VALUE form", n, line)
+		}
+
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("env: parsing dotenv: line %d: %w", n, err)
+		}
+
+		m[key] = value
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("env: reading dotenv: %w", err)
+	}
+
+	return m, nil
+}
+
+// ReadDotenv reads and parses the dotenv file named by name. See [ParseDotenv]
+// documentation for the supported syntax.
+func ReadDotenv(name string) (Map, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("env: opening dotenv: %w", err)
+	}
+	defer f.Close()
+
+	return ParseDotenv(f)
+}
+
+// unquote removes the quotes surrounding s, if any, and interprets escape
+// sequences for double-quoted strings.
+func unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return s, nil
+	}
+
+	switch first, last := s[0], s[len(s)-1]; {
+	case first == '"' && last == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("unquoting value: %w", err)
+		}
+		return v, nil
+	case first == '\'' && last == '\'':
+		return s[1 : len(s)-1], nil
+	default:
+		return s, nil
+	}
+}
@@ -0,0 +1,60 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestParseDotenv(t *testing.T) {
+	t.Run("valid syntax", func(t *testing.T) {
+		const dotenv = `
+# comment
+HOST=localhost
+export PORT = 8080
+GREETING="hello\nworld"
+PATTERN='$HOME\n'
+EMPTY=
+`
+		m, err := env.ParseDotenv(strings.NewReader(dotenv))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, m, env.Map{
+			"HOST":     "localhost",
+			"PORT":     "8080",
+			"GREETING": "hello\nworld",
+			"PATTERN":  `$HOME\n`,
+			"EMPTY":    "",
+		})
+	})
+
+	t.Run("invalid syntax", func(t *testing.T) {
+		test := func(name, dotenv string) {
+			t.Run(name, func(t *testing.T) {
+				_, err := env.ParseDotenv(strings.NewReader(dotenv))
+				assert.True[E](t, err != nil)
+			})
+		}
+
+		test("missing separator", "HOST")
+		test("empty key", "=localhost")
+		test("invalid quotes", `HOST="local"host"`)
+	})
+}
+
+func TestReadDotenv(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(name, []byte("PORT=8080\n"), 0o600)
+	assert.NoErr[F](t, err)
+
+	m, err := env.ReadDotenv(name)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, m, env.Map{"PORT": "8080"})
+
+	_, err = env.ReadDotenv(filepath.Join(t.TempDir(), "missing.env"))
+	assert.IsErr[E](t, err, os.ErrNotExist)
+}
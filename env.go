@@ -8,7 +8,10 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ErrInvalidArgument is returned when the argument provided to
@@ -19,6 +22,10 @@ var ErrInvalidArgument = errors.New("env: argument must be a non-nil struct poin
 // environment variable is empty.
 var ErrEmptyTagName = errors.New("env: empty tag name is not allowed")
 
+// ErrDuplicateName is returned when several struct fields are mapped to the
+// same environment variable.
+var ErrDuplicateName = errors.New("env: duplicate variable name")
+
 // ErrUnsupportedType is returned when the provided struct contains a field of
 // an unsupported type.
 var ErrUnsupportedType = errors.New("env: unsupported type")
@@ -27,29 +34,85 @@ var ErrUnsupportedType = errors.New("env: unsupported type")
 // option, e.g. `env:"VAR,invalid"`.
 var ErrInvalidTagOption = errors.New("env: invalid tag option")
 
+// UnknownError is returned when the environment contains variables starting
+// with the prefix configured by [WithStrictPrefix] that are not defined in the
+// provided struct.
+type UnknownError struct {
+	// Names is a slice of the names of the unknown environment variables.
+	Names []string
+}
+
+// Error implements the error interface.
+func (e *UnknownError) Error() string {
+	return fmt.Sprintf("env: %v are unknown", e.Names)
+}
+
 // NotSetError is returned when environment variables are marked as required but
 // not set.
 type NotSetError struct {
 	// Names is a slice of the names of the missing required environment
 	// variables.
 	Names []string
+
+	format func(error) string // set by [WithErrorFormatter].
 }
 
 // Error implements the error interface.
 func (e *NotSetError) Error() string {
+	if e.format != nil {
+		c := *e
+		c.format = nil
+		return e.format(&c)
+	}
 	return fmt.Sprintf("env: %v are required but not set", e.Names)
 }
 
-// Load loads environment variables into the provided struct using the [OS]
-// [Provider] as their source. To specify a custom [Provider], use the
-// [LoadFrom] function. dst must be a non-nil struct pointer, otherwise Load
-// returns [ErrInvalidArgument].
+// ParseError is returned when the value of an environment variable cannot be
+// parsed into the type of the corresponding struct field.
+type ParseError struct {
+	// Name is the name of the environment variable.
+	Name string
+	// Err is the underlying parsing error, e.g. [strconv.ErrSyntax].
+	Err error
+
+	format func(error) string // set by [WithErrorFormatter].
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	if e.format != nil {
+		c := *e
+		c.format = nil
+		return e.format(&c)
+	}
+	return fmt.Sprintf("env: parsing %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying parsing error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Unmarshaler is the interface implemented by types that can load themselves
+// from environment variables. If the argument provided to [Load]/[LoadFrom]
+// implements it, its UnmarshalEnv method is called instead of the
+// reflection-based loading, and the options are ignored. It is an escape hatch
+// for exotic types and generated code.
+type Unmarshaler interface {
+	UnmarshalEnv(p Provider) error
+}
+
+// Load loads environment variables into the provided struct using the default
+// [Provider] as their source, which is [OS] unless changed using
+// [SetDefaultProvider]. To specify a custom [Provider], use the [LoadFrom]
+// function. dst must be a non-nil struct pointer, otherwise Load
+// returns [ErrInvalidArgument]. If dst implements the [Unmarshaler] interface,
+// its UnmarshalEnv method is called instead.
 //
 // The struct fields must have the `env:"VAR"` struct tag, where VAR is the name
 // of the corresponding environment variable. Unexported fields and fields
 // without this tag (except nested structs) are ignored. If the tag is found
 // but the name of the environment variable is empty, the error will be
-// [ErrEmptyTagName].
+// [ErrEmptyTagName]. If several fields are mapped to the same environment
+// variable, the error will be [ErrDuplicateName].
 //
 // The following types are supported as struct fields:
 //
@@ -75,10 +138,32 @@ func (e *NotSetError) Error() string {
 //
 //   - required: marks the environment variable as required
 //   - expand: expands the value of the environment variable using [os.Expand]
+//     (or the syntax set by [WithExpansionStyle]); the referenced variables
+//     are expanded recursively and looked up once per call, a reference cycle
+//     (e.g. A=$B, B=$A) is reported as an error
+//   - secret: marks the environment variable as secret, its value is redacted
+//     in the output of the helpers like [Diff]
+//   - lazy: defers the lookup until the first use, the field must be of type
+//     [Lazy]
+//   - file: treats the value as the name of a file, which is opened on the
+//     first read; the field must be of type [io.Reader] or [io.ReadCloser]
+//   - template: treats the `default` tag as a template, see below
+//
+// The `default` tag of the variable marked with the template option can
+// reference other struct fields using the [text/template] syntax, e.g.
+// `env:"URL,template" default:"http://{{.Host}}:{{.Port}}"`, where the dot is
+// the struct passed to Load. Such defaults are resolved after all the other
+// variables, in the order of their references; a reference cycle is reported
+// as an error. Without the option, the `default` tag is used as is.
+//
+// Previous names of the environment variable can be listed in the `deprecated`
+// tag, e.g. `env:"DB_HOST" deprecated:"DATABASE_HOST,DBHOST"`. They are tried
+// in order if the variable is not set.
 //
 // If environment variables are marked as required but not set, an error of type
-// [NotSetError] will be returned. If the tag contains an invalid option, the
-// error will be [ErrInvalidTagOption].
+// [NotSetError] will be returned. If the value of an environment variable
+// cannot be parsed, an error of type [ParseError] will be returned. If the tag
+// contains an invalid option, the error will be [ErrInvalidTagOption].
 //
 // In addition to the tag-level options, Load also supports the following
 // function-level options:
@@ -87,19 +172,92 @@ func (e *NotSetError) Error() string {
 //   - [WithSliceSeparator]: sets custom separator to parse slice values
 //   - [WithStrictMode]: enables strict mode: no `default` tag == required
 //   - [WithUsageOnError]: enables a usage message printing when an error occurs
+//   - [WithUsageOrder]/[WithUsageRequiredOnly]: customize the usage message
+//   - [WithSources]: records the source of each environment variable
+//   - [WithFilter]/[WithGroups]: loads only the matching environment variables
+//   - [WithStrictPrefix]: reports unknown variables starting with the prefix
+//   - [WithUnusedReport]: reports variables that were never looked up
+//   - [WithLogger]: logs each resolved variable using [log/slog] (Go 1.21+)
+//   - [WithTrace]: runs instrumentation hooks, e.g. to record spans
+//   - [WithWarnings]: reports non-fatal issues, e.g. deprecated names used
+//   - [WithConcurrency]: looks up the variables concurrently
+//   - [WithErrorFormatter]: customizes the messages of the returned errors
+//   - [WithExpansionStyle]: sets the syntax recognized by the expand option
 //
 // See their documentation for details.
 func Load(dst any, opts ...Option) error {
-	return newLoader(OS, opts...).loadVars(dst)
+	return newLoader(nil, opts...).loadVars(dst)
 }
 
 // LoadFrom loads environment variables into the provided struct using the
-// specified [Provider] as their source. See [Load] documentation for more
-// details.
+// specified [Provider] as their source. If p is nil, the default [Provider] is
+// used. See [Load] documentation for more details.
 func LoadFrom(p Provider, dst any, opts ...Option) error {
 	return newLoader(p, opts...).loadVars(dst)
 }
 
+// LoadAll loads environment variables into a new struct of type T once per
+// each provided prefix using the specified [Provider] as their source, e.g. to
+// load the same config for several tenants (TENANT_A_, TENANT_B_, etc). It
+// returns the loaded structs keyed by their prefixes. The prefix is applied the
+// same way [WithPrefix] does it and overrides the one set using this option.
+// If an error occurs, it is returned along with the prefix. See [Load]
+// documentation for more details.
+func LoadAll[T any](p Provider, prefixes []string, opts ...Option) (map[string]T, error) {
+	m := make(map[string]T, len(prefixes))
+
+	for _, prefix := range prefixes {
+		var dst T
+		if err := LoadFrom(p, &dst, append(opts[:len(opts):len(opts)], WithPrefix(prefix))...); err != nil {
+			return nil, fmt.Errorf("env: loading %q: %w", prefix, err)
+		}
+		m[prefix] = dst
+	}
+
+	return m, nil
+}
+
+// LoadMap loads the raw values of the environment variables named by the
+// provided keys using the specified [Provider] as their source. It is useful
+// for dynamic cases, where the set of variables is not known at compile time.
+// Unset variables are omitted from the result. The following options are
+// respected:
+//
+//   - [WithPrefix]: the prefix is added to each key when looking it up, but not
+//     to the keys of the result
+//   - [WithStrictMode]: all the keys are treated as required
+//   - [WithSources]: the source of each found variable is recorded
+//
+// If the variables are required but not set, an error of type [NotSetError]
+// will be returned.
+func LoadMap(p Provider, keys []string, opts ...Option) (Map, error) {
+	l := newLoader(p, opts...)
+
+	m := make(Map, len(keys))
+	var notset []string
+
+	for _, key := range keys {
+		name := l.prefix + key
+		value, source, ok, _ := l.lookupEnv(name, false)
+		if !ok {
+			if l.strictMode {
+				notset = append(notset, name)
+			}
+			continue
+		}
+		if l.sources != nil {
+			l.sources[name] = source
+		}
+		m[key] = value
+	}
+
+	if len(notset) > 0 {
+		return nil, l.formatError(&NotSetError{Names: notset})
+	}
+
+	return m, nil
+}
+
 // Option allows to customize the behaviour of the [Load]/[LoadFrom] functions.
 type Option func(*loader)
 
@@ -130,18 +288,220 @@ func WithUsageOnError(w io.Writer) Option {
 	return func(l *loader) { l.usageOutput = w }
 }
 
+// UsageOrder is the order of the environment variables in the usage message,
+// see [WithUsageOrder].
+type UsageOrder int
+
+// The supported orders of the environment variables in the usage message.
+const (
+	OrderDeclaration  UsageOrder = iota // OrderDeclaration is the order of the struct fields declaration (default).
+	OrderAlphabetical                   // OrderAlphabetical sorts the variables by their names.
+	OrderGroup                          // OrderGroup sorts the variables by their groups, see the `group` tag.
+)
+
+// WithUsageOrder configures the order of the environment variables in the
+// usage message printed by [PrintUsage] or [Load]/[LoadFrom] (if the
+// [WithUsageOnError] option is provided). The variables without a group come
+// first in [OrderGroup], and the order of declaration is preserved within each
+// group.
+func WithUsageOrder(order UsageOrder) Option {
+	return func(l *loader) { l.usageOrder = order }
+}
+
+// WithUsageRequiredOnly configures the usage message printed by [PrintUsage]
+// or [Load]/[LoadFrom] (if the [WithUsageOnError] option is provided) to
+// document only the required environment variables. Unlike [WithFilter], it
+// does not affect loading.
+func WithUsageRequiredOnly() Option {
+	return func(l *loader) { l.usageRequiredOnly = true }
+}
+
+// WithSources configures [Load]/[LoadFrom] to record the source of each loaded
+// environment variable into the provided map, using the name of the variable
+// as the key. The source is the name of the [Provider] that supplied the value
+// (see [Named]), "env" for unnamed providers, or "default" if the default value
+// is used. The map must not be nil.
+func WithSources(m map[string]string) Option {
+	return func(l *loader) { l.sources = m }
+}
+
+// WithFilter configures [Load]/[LoadFrom] to load only the environment
+// variables for which the provided predicate returns true. The rest of the
+// struct fields are left untouched. By default, all variables are loaded.
+func WithFilter(f func(Var) bool) Option {
+	return func(l *loader) { l.filter = f }
+}
+
+// WithGroups configures [Load]/[LoadFrom] to load only the environment
+// variables that belong to one of the provided groups. A group is set using the
+// `group` tag, either on the field itself or on the parent nested struct, e.g.
+// `group:"db"`. It is a shortcut for [WithFilter].
+func WithGroups(groups ...string) Option {
+	return WithFilter(func(v Var) bool {
+		for _, group := range groups {
+			if v.Group == group {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithStrictPrefix configures [Load]/[LoadFrom] to return an error of type
+// [UnknownError], if the environment contains variables starting with the
+// provided prefix that are not defined in the struct, e.g. due to a typo. The
+// [Provider] must implement the [Lister] interface, otherwise no variables are
+// reported. By default, unknown variables are ignored.
+func WithStrictPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.strictPrefix = prefix
+		l.checkUnknown = true
+	}
+}
+
+// WithUnusedReport configures [Load]/[LoadFrom] to report the names of the
+// environment variables starting with the provided prefix that were present
+// but never looked up, including those skipped due to [WithFilter]. The sorted
+// names are stored into the provided slice pointer, which must not be nil. It
+// is useful to find dead configuration in deployment manifests. The [Provider]
+// must implement the [Lister] interface, otherwise no variables are reported.
+func WithUnusedReport(prefix string, names *[]string) Option {
+	return func(l *loader) {
+		l.unusedPrefix = prefix
+		l.unused = names
+	}
+}
+
+// Warning is a non-fatal issue found while loading environment variables. See
+// [WithWarnings] for details.
+type Warning struct {
+	Name    string // Name is the name of the environment variable.
+	Message string // Message describes the issue.
+}
+
+// String implements the [fmt.Stringer] interface.
+func (w Warning) String() string {
+	return fmt.Sprintf("env: %s: %s", w.Name, w.Message)
+}
+
+// WithWarnings configures [Load]/[LoadFrom] to append non-fatal issues found
+// while loading environment variables to the provided slice, which must not be
+// nil. The following issues are reported:
+//
+//   - a deprecated name of the variable is used (see the `deprecated` tag)
+//   - the value of the variable has leading or trailing whitespace
+//
+// By default, such issues are silently ignored.
+func WithWarnings(warnings *[]Warning) Option {
+	return func(l *loader) { l.warnings = warnings }
+}
+
+// WithLocker configures the functions that update the provided struct in the
+// background (e.g. [Refresh]) to hold the lock while updating the fields, so
+// that the struct can be safely read concurrently. [Handler] holds the lock
+// while reading the struct. By default, no locking is performed.
+func WithLocker(mu sync.Locker) Option {
+	return func(l *loader) { l.locker = mu }
+}
+
+// WithRevealedSecrets configures the functions that redact the values of the
+// variables marked as secret (e.g. [Dump], [WriteShell] or [Plan]) to include
+// them as is. Use with caution: the output should not be logged or committed.
+func WithRevealedSecrets() Option {
+	return func(l *loader) { l.revealSecrets = true }
+}
+
+// WithConcurrency configures [Load]/[LoadFrom] to look up the environment
+// variables using up to n concurrent workers before parsing them, which cuts
+// the loading time when the [Provider] is remote (e.g. a secrets manager) and
+// the struct has many fields. The [Provider] must be safe for concurrent use.
+// The results are the same as without this option, including [WithSources]
+// and [WithWarnings]. If n is less than 2, the variables are looked up
+// sequentially, which is the default.
+func WithConcurrency(n int) Option {
+	return func(l *loader) { l.concurrency = n }
+}
+
+// WithErrorFormatter configures [Load]/[LoadFrom] to render the messages of the
+// returned errors of types [NotSetError] and [ParseError] using f, e.g. to
+// localize them or to attach links to runbooks. f is called by the Error method
+// with a copy of the error, whose own Error method returns the default message:
+//
+//	env.WithErrorFormatter(func(err error) string {
+//		return err.Error() + " (see https://wiki.example.com/config)"
+//	})
+//
+// The errors are still matched by [errors.As] as usual. The errors returned by
+// [Lazy.Get] and [LoadMap] are rendered using f as well.
+func WithErrorFormatter(f func(err error) string) Option {
+	return func(l *loader) { l.errorFormatter = f }
+}
+
+// ExpansionStyle is the syntax of the references to other environment variables
+// recognized by the `expand` option, see [WithExpansionStyle].
+type ExpansionStyle int
+
+// The supported syntaxes of the references to other environment variables.
+const (
+	ExpandUnix    ExpansionStyle = iota // ExpandUnix recognizes $VAR and ${VAR}, see [os.Expand] (default).
+	ExpandWindows                       // ExpandWindows recognizes %VAR%, as in Windows batch files; %% is replaced with %.
+	ExpandBoth                          // ExpandBoth recognizes both ExpandUnix and ExpandWindows syntaxes.
+)
+
+// WithExpansionStyle configures the syntax of the references to other
+// environment variables recognized in the values of the variables marked with
+// the `expand` option. It is useful for values copied from Windows batch files
+// or IIS configs, which use the %VAR% syntax. The names in the %VAR% syntax
+// may only contain letters, digits and underscores; other percent signs are
+// left as is. The default style is [ExpandUnix].
+func WithExpansionStyle(style ExpansionStyle) Option {
+	return func(l *loader) { l.expansionStyle = style }
+}
+
+// ExpandValue replaces the references to other environment variables in value,
+// the value of the variable named by key, the same way the `expand` option does
+// it: the referenced variables are retrieved from the specified [Provider] and
+// expanded recursively, and a reference cycle is reported as an error. Only the
+// [WithExpansionStyle] option is respected. It is intended for generated code,
+// see cmd/envgen.
+func ExpandValue(p Provider, key, value string, opts ...Option) (string, error) {
+	return newLoader(p, opts...).expand(value, []string{key})
+}
+
 // loader is an environment variables loader.
 type loader struct {
-	provider    Provider
-	prefix      string
-	sliceSep    string
-	strictMode  bool
-	usageOutput io.Writer
+	provider          Provider
+	prefix            string
+	sliceSep          string
+	strictMode        bool
+	usageOutput       io.Writer
+	usageOrder        UsageOrder
+	usageRequiredOnly bool
+	sources           map[string]string
+	filter            func(Var) bool
+	strictPrefix      string
+	checkUnknown      bool
+	unusedPrefix      string
+	unused            *[]string
+	dryRun            bool
+	resolveHooks      []func(PlanEntry) // called for each resolved variable.
+	trace             *Trace
+	warnings          *[]Warning
+	locker            sync.Locker
+	revealSecrets     bool
+	concurrency       int
+	expansionStyle    ExpansionStyle
+	expanded          map[string]string // the memoized expanded values, see [loader.expand].
+	errorFormatter    func(error) string
 }
 
-// newLoader creates a new loader with the specified [Provider] and applies the
-// provided options, which override the default settings.
+// newLoader creates a new loader with the specified [Provider] (or the default
+// one, if p is nil) and applies the provided options, which override the
+// default settings.
 func newLoader(p Provider, opts ...Option) *loader {
+	if p == nil {
+		p = DefaultProvider()
+	}
 	l := loader{
 		provider:    p,
 		prefix:      "",
@@ -157,19 +517,85 @@ func newLoader(p Provider, opts ...Option) *loader {
 
 // loadVars loads environment variables into the provided struct.
 func (l *loader) loadVars(dst any) (err error) {
+	if u, ok := dst.(Unmarshaler); ok {
+		// dry run only: unmarshal into a copy to keep the target untouched.
+		if rv := reflect.ValueOf(dst); l.dryRun && rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			dup := reflect.New(rv.Elem().Type())
+			dup.Elem().Set(rv.Elem())
+			u = dup.Interface().(Unmarshaler)
+		}
+		return u.UnmarshalEnv(l.provider)
+	}
+
 	rv := reflect.ValueOf(dst)
 	if !structPtr(rv) {
 		return ErrInvalidArgument
 	}
 
-	vars, err := l.parseVars(rv.Elem())
+	// the Lazy values are looked up on the first use, bypassing the wrappers
+	// below (e.g. the one configured by [WithTrace]).
+	provider := l.provider
+
+	if l.trace != nil {
+		done := l.startTrace()
+		defer func() { done(err) }()
+	}
+
+	if l.errorFormatter != nil {
+		defer func() { err = l.formatError(err) }()
+	}
+
+	// the memoized expanded values are only valid within a single pass.
+	l.expanded = nil
+
+	// dry run only: load into a copy to keep the target struct untouched.
+	if l.dryRun {
+		dup := reflect.New(rv.Elem().Type())
+		dup.Elem().Set(rv.Elem())
+		rv = dup
+	}
+
+	vars, err := l.parseVars(rv.Elem(), "")
 	if err != nil {
 		return err
 	}
 
+	if l.checkUnknown {
+		if unknown := unknownKeys(l.provider, l.strictPrefix, vars); len(unknown) > 0 {
+			return &UnknownError{Names: unknown}
+		}
+	}
+
+	// the usage message documents the results of the default templates, which
+	// must be rendered before the struct is modified.
+	if l.usageOutput != nil && hasTemplates(vars) {
+		rendered, _ := renderDefaults(Map{}, dst, l)
+		setRenderedDefaults(vars, rendered)
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+
+	if l.unused != nil {
+		tp := &trackingProvider{Provider: l.provider, seen: make(map[string]bool)}
+		for _, v := range vars {
+			if v.lazy {
+				tp.markSeen(v.Name)
+			}
+		}
+		l.provider = tp
+		defer func() { *l.unused = tp.unseenKeys(l.unusedPrefix) }()
+	}
+
+	if l.concurrency > 1 {
+		eager := filterVars(vars, func(v Var) bool { return !v.lazy })
+		l.provider = prefetch(l.provider, eager, l.concurrency)
+	}
+
 	defer func() {
 		if err != nil && l.usageOutput != nil {
-			Usage(l.usageOutput, vars)
+			Usage(l.usageOutput, l.usageVars(vars))
 		}
 	}()
 
@@ -177,8 +603,22 @@ func (l *loader) loadVars(dst any) (err error) {
 	// to return NotSetError after the loop is finished.
 	var notset []string
 
+	// the variables using their default templates, which are resolved after
+	// the loop is finished, when the referenced fields are set.
+	var pending []Var
+
 	for _, v := range vars {
-		value, ok := l.lookupEnv(v.Name, v.Expand)
+		if v.lazy {
+			if !l.dryRun {
+				l.initLazy(v, provider)
+			}
+			continue
+		}
+
+		value, source, ok, err := l.lookupVar(v)
+		if err != nil {
+			return err
+		}
 		if !ok {
 			// if the variable is required, mark it as missing and skip the iteration...
 			if v.Required {
@@ -186,51 +626,238 @@ func (l *loader) loadVars(dst any) (err error) {
 				continue
 			}
 			// ...otherwise, use the default value.
-			// TODO(junk1tm): actually, there is no need to set a default value
-			//                if it has been obtained from the initialized struct field.
-			value = v.Default
+			if v.tmpl != nil {
+				pending = append(pending, v)
+				continue
+			}
+			value, source = v.Default, sourceDefault
 		}
 
-		if kindOf(v.field, reflect.Slice) && !implements(v.field, unmarshalerIface) {
-			err = setSlice(v.field, strings.Split(value, l.sliceSep))
-		} else {
-			err = setValue(v.field, value)
-		}
-		if err != nil {
+		if err := l.setVar(v, value, source, !ok); err != nil {
 			return err
 		}
 	}
 
+	// the templates are executed even if some variables are missing, so that
+	// the dry run reports their results (see renderDefaults).
+	err = l.resolveTemplates(rv, pending)
+
 	if len(notset) > 0 {
 		return &NotSetError{Names: notset}
 	}
 
+	return err
+}
+
+// formatError configures err to be rendered using the formatter set by
+// [WithErrorFormatter], if it is of type [NotSetError] or [ParseError].
+func (l *loader) formatError(err error) error {
+	if l.errorFormatter == nil {
+		return err
+	}
+	switch e := err.(type) {
+	case *NotSetError:
+		e.format = l.errorFormatter
+	case *ParseError:
+		e.format = l.errorFormatter
+	}
+	return err
+}
+
+// setVar parses value into the field of the provided variable, then records
+// its source and runs the resolve hooks. def is true, if value is the default
+// one.
+func (l *loader) setVar(v Var, value, source string, def bool) error {
+	set := l.setField
+	if v.file {
+		set = setFile
+	}
+
+	// the default value obtained from the initialized struct field is already
+	// set, and parsing it back may fail, e.g. "<nil>" for a nil net.IP.
+	if _, ok := v.tag.Lookup("default"); ok || !def {
+		if err := set(v.field, value); err != nil {
+			if errors.Is(err, ErrUnsupportedType) {
+				return err
+			}
+			return &ParseError{Name: v.Name, Err: err}
+		}
+	}
+
+	if l.sources != nil {
+		l.sources[v.Name] = source
+	}
+
+	if len(l.resolveHooks) > 0 {
+		if v.Secret && !l.revealSecrets {
+			value = redacted
+		}
+		entry := PlanEntry{Var: v, Value: value, Source: source, Default: def}
+		for _, hook := range l.resolveHooks {
+			hook(entry)
+		}
+	}
+
 	return nil
 }
 
+// setField parses value based on field's type and sets field's underlying value
+// to the result.
+func (l *loader) setField(field reflect.Value, value string) error {
+	if kindOf(field, reflect.Slice) && !implements(field, unmarshalerIface) {
+		return setSlice(field, strings.Split(value, l.sliceSep))
+	}
+	return setValue(field, value)
+}
+
 // parseVars parses environment variables from the fields of the provided
-// struct.
-func (l *loader) parseVars(v reflect.Value) ([]Var, error) {
-	var vars []Var
+// struct. path is the path of the struct itself, it is used to build the path
+// of each field. The metadata of the struct type is parsed once and cached, so
+// that only the options and the current values of the fields are applied here.
+func (l *loader) parseVars(v reflect.Value, path string) ([]Var, error) {
+	meta := typeMetaOf(v.Type())
+	if meta.err != nil {
+		return nil, meta.err
+	}
+
+	vars := make([]Var, 0, len(meta.fields))
+	for _, fm := range meta.fields {
+		field := v.FieldByIndex(fm.index)
+		required := fm.required
 
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		if !field.CanSet() {
+		// the value from the `default` tag has a higher priority.
+		defValue := fm.defValue
+		if !fm.defSet && !fm.lazy && !fm.file {
+			defValue = formatDefault(field)
+		}
+
+		typ := field.Type()
+		if fm.lazy {
+			typ = fm.lazyType
+		}
+
+		// strict mode only: no `default` tag means the variable is required.
+		if l.strictMode && !fm.defSet {
+			required = true
+		}
+
+		// the variable is either required or has a default value, but not both.
+		if required {
+			defValue = ""
+		}
+
+		var deprecated []string
+		for _, name := range fm.deprecated {
+			deprecated = append(deprecated, l.prefix+name)
+		}
+
+		vars = append(vars, Var{
+			Name:       l.prefix + fm.name,
+			Type:       typ,
+			Desc:       fm.desc,
+			Example:    fm.example,
+			Default:    defValue,
+			Required:   required,
+			Expand:     fm.expand,
+			Secret:     fm.secret,
+			Group:      fm.group,
+			Deprecated: deprecated,
+			TTL:        fm.ttl,
+			Field:      path + fm.path,
+			field:      field,
+			tag:        fm.tag,
+			lazy:       fm.lazy,
+			file:       fm.file,
+			tmpl:       fm.defTmpl,
+		})
+	}
+
+	return vars, nil
+}
+
+// typeCache caches the metadata of the struct types parsed by [typeMetaOf].
+// The metadata does not depend on the options, so it is shared by all loaders.
+var typeCache sync.Map // map[reflect.Type]*typeMeta
+
+// typeMeta is the cached metadata of a struct type.
+type typeMeta struct {
+	fields []fieldMeta
+	err    error // the error occurred while parsing the struct tags, if any.
+}
+
+// fieldMeta is the metadata of a struct field parsed from its tags, before any
+// options are applied.
+type fieldMeta struct {
+	index      []int  // the index sequence for [reflect.Value.FieldByIndex].
+	path       string // the path of the field, e.g. "DB.Port".
+	tag        reflect.StructTag
+	name       string // the name of the variable, without prefix.
+	desc       string
+	example    string
+	required   bool
+	expand     bool
+	secret     bool
+	lazy       bool
+	lazyType   reflect.Type // the type of the [Lazy] value.
+	file       bool
+	template   bool // whether the `default` tag is a template.
+	group      string
+	deprecated []string // the previous names of the variable, without prefix.
+	ttl        time.Duration
+	defValue   string
+	defSet     bool             // whether the `default` tag is set.
+	defTmpl    *defaultTemplate // the parsed `default` tag, if it references other fields.
+}
+
+// typeMetaOf returns the metadata of the struct type t, parsing it on the first
+// call.
+func typeMetaOf(t reflect.Type) *typeMeta {
+	if meta, ok := typeCache.Load(t); ok {
+		return meta.(*typeMeta)
+	}
+
+	var meta typeMeta
+	meta.fields, meta.err = parseStruct(t, nil, "", "")
+	if meta.err == nil {
+		meta.err = checkDuplicates(meta.fields)
+	}
+
+	// another goroutine may have parsed the same type concurrently.
+	actual, _ := typeCache.LoadOrStore(t, &meta)
+	return actual.(*typeMeta)
+}
+
+// parseStruct is the recursive implementation of typeMetaOf. index and path
+// are the index sequence and the path of the struct itself. group is the group
+// inherited from the parent struct, if any.
+func parseStruct(t reflect.Type, index []int, path, group string) ([]fieldMeta, error) {
+	var fields []fieldMeta
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
 			// skip unexported fields.
 			continue
 		}
 
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		// the `group` tag of the field itself has a higher priority.
+		fieldGroup := group
+		if g, ok := sf.Tag.Lookup("group"); ok {
+			fieldGroup = g
+		}
+
 		// special case: a nested struct, parse its fields recursively.
-		if kindOf(field, reflect.Struct) && !implements(field, unmarshalerIface) {
-			nested, err := l.parseVars(field)
+		if sf.Type.Kind() == reflect.Struct && !typeImplements(sf.Type, unmarshalerIface, lazyIface) {
+			nested, err := parseStruct(sf.Type, fieldIndex, path+sf.Name+".", fieldGroup)
 			if err != nil {
 				return nil, err
 			}
-			vars = append(vars, nested...)
+			fields = append(fields, nested...)
 			continue
 		}
 
-		sf := v.Type().Field(i)
 		value, ok := sf.Tag.Lookup("env")
 		if !ok {
 			// skip fields without the `env` tag.
@@ -243,65 +870,289 @@ func (l *loader) parseVars(v reflect.Value) ([]Var, error) {
 			return nil, ErrEmptyTagName
 		}
 
-		var required, expand bool
+		fm := fieldMeta{
+			index:   fieldIndex,
+			path:    path + sf.Name,
+			tag:     sf.Tag,
+			name:    name,
+			desc:    sf.Tag.Get("desc"),
+			example: sf.Tag.Get("example"),
+			group:   fieldGroup,
+		}
+
 		for _, option := range options {
 			switch option {
 			case "required":
-				required = true
+				fm.required = true
 			case "expand":
-				expand = true
+				fm.expand = true
+			case "secret":
+				fm.secret = true
+			case "lazy":
+				fm.lazy = true
+			case "file":
+				fm.file = true
+			case "template":
+				fm.template = true
 			default:
 				return nil, fmt.Errorf("%w %q", ErrInvalidTagOption, option)
 			}
 		}
 
-		// the value from the `default` tag has a higher priority.
-		defValue, defSet := sf.Tag.Lookup("default")
-		if !defSet {
-			defValue = fmt.Sprintf("%v", field.Interface())
+		// the `lazy` option and the Lazy type must be used together.
+		switch isLazy := typeImplements(sf.Type, lazyIface); {
+		case fm.lazy && !isLazy:
+			return nil, fmt.Errorf("%w %q: %s must be of type env.Lazy", ErrInvalidTagOption, "lazy", name)
+		case !fm.lazy && isLazy:
+			return nil, fmt.Errorf("%w: %s of type env.Lazy requires the %q option", ErrInvalidTagOption, name, "lazy")
+		case isLazy:
+			fm.lazyType = reflect.New(sf.Type).Interface().(lazyValue).lazyType()
 		}
 
-		// strict mode only: no `default` tag means the variable is required.
-		if l.strictMode && !defSet {
-			required = true
+		// the `file` option and the reader types must be used together.
+		switch isReader := sf.Type == readerType || sf.Type == readCloserType; {
+		case fm.file && !isReader:
+			return nil, fmt.Errorf("%w %q: %s must be of type io.Reader or io.ReadCloser", ErrInvalidTagOption, "file", name)
+		case !fm.file && isReader:
+			return nil, fmt.Errorf("%w: %s of type %s requires the %q option", ErrInvalidTagOption, name, sf.Type, "file")
 		}
 
-		// the variable is either required or has a default value, but not both.
-		if required {
-			defValue = ""
+		if names, ok := sf.Tag.Lookup("deprecated"); ok {
+			for _, name := range strings.Split(names, ",") {
+				if name == "" {
+					return nil, ErrEmptyTagName
+				}
+				fm.deprecated = append(fm.deprecated, name)
+			}
 		}
 
-		vars = append(vars, Var{
-			Name:     l.prefix + name,
-			Type:     field.Type(),
-			Desc:     sf.Tag.Get("desc"),
-			Default:  defValue,
-			Required: required,
-			Expand:   expand,
-			field:    field,
-		})
+		if s, ok := sf.Tag.Lookup("ttl"); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("env: invalid ttl %q of %s", s, name)
+			}
+			fm.ttl = d
+		}
+
+		fm.defValue, fm.defSet = sf.Tag.Lookup("default")
+
+		if fm.template {
+			switch {
+			case !fm.defSet:
+				return nil, fmt.Errorf("%w %q: %s requires the `default` tag", ErrInvalidTagOption, "template", name)
+			case fm.lazy:
+				return nil, fmt.Errorf("%w %q: %s cannot be lazy", ErrInvalidTagOption, "template", name)
+			}
+			tmpl, err := parseDefaultTemplate(name, fm.defValue)
+			if err != nil {
+				return nil, err
+			}
+			fm.defTmpl = tmpl
+		}
+
+		fields = append(fields, fm)
 	}
 
-	return vars, nil
+	return fields, nil
+}
+
+// checkDuplicates returns [ErrDuplicateName] wrapped with the paths of both
+// fields, if several fields are mapped to the same variable. The prefix set by
+// [WithPrefix] is the same for all fields, so the names are compared without it.
+func checkDuplicates(fields []fieldMeta) error {
+	paths := make(map[string]string, len(fields))
+	for _, fm := range fields {
+		if path, ok := paths[fm.name]; ok {
+			return fmt.Errorf("%w %q: %s and %s", ErrDuplicateName, fm.name, path, fm.path)
+		}
+		paths[fm.name] = fm.path
+	}
+	return nil
+}
+
+// unknownKeys returns the sorted names of the environment variables provided
+// by p that start with the prefix but do not belong to vars.
+func unknownKeys(p Provider, prefix string, vars []Var) []string {
+	known := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		known[v.Name] = true
+		for _, name := range v.Deprecated {
+			known[name] = true
+		}
+	}
+
+	var unknown []string
+	for _, key := range listKeys(p) {
+		if strings.HasPrefix(key, prefix) && !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// filterVars returns the vars for which f returns true.
+func filterVars(vars []Var, f func(Var) bool) []Var {
+	var filtered []Var
+	for _, v := range vars {
+		if f(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// lookupVar retrieves the value of the provided environment variable. If the
+// variable is not set, its deprecated names are tried in order. It also reports
+// the warnings related to the variable, if requested. An error is returned
+// only if the value cannot be expanded.
+func (l *loader) lookupVar(v Var) (string, string, bool, error) {
+	name := v.Name
+	value, source, ok, err := l.lookupEnv(name, v.Expand)
+
+	for i := 0; !ok && err == nil && i < len(v.Deprecated); i++ {
+		name = v.Deprecated[i]
+		if value, source, ok, err = l.lookupEnv(name, v.Expand); ok {
+			l.warn(v.Name, fmt.Sprintf("%s is deprecated, use %s instead", name, v.Name))
+		}
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+
+	if ok && value != strings.TrimSpace(value) {
+		l.warn(v.Name, fmt.Sprintf("the value of %s has leading or trailing whitespace", name))
+	}
+
+	return value, source, ok, nil
+}
+
+// warn reports a warning related to the environment variable named by name, if
+// the [WithWarnings] option is provided.
+func (l *loader) warn(name, msg string) {
+	if l.warnings != nil {
+		*l.warnings = append(*l.warnings, Warning{Name: name, Message: msg})
+	}
 }
 
 // lookupEnv retrieves the value of the environment variable named by the key
-// using the internal [Provider]. It replaces $VAR or ${VAR} in the result
-// using [os.Expand] if expand is true.
-func (l *loader) lookupEnv(key string, expand bool) (string, bool) {
-	value, ok := l.provider.LookupEnv(key)
+// using the internal [Provider] and reports its source. It replaces $VAR or
+// ${VAR} in the result if expand is true, see [loader.expand].
+func (l *loader) lookupEnv(key string, expand bool) (string, string, bool, error) {
+	value, source, ok := lookupSource(l.provider, key)
 	if !ok {
-		return "", false
+		return "", "", false, nil
 	}
 
 	if !expand {
-		return value, true
+		return value, source, true, nil
+	}
+
+	value, err := l.expand(value, []string{key})
+	if err != nil {
+		return "", "", false, err
 	}
+	return value, source, true, nil
+}
+
+// expand replaces $VAR or ${VAR} (or %VAR%, see [WithExpansionStyle]) in value.
+// The referenced variables are expanded recursively; their expanded values are
+// memoized, so that each one is looked up only once per loader. stack is the
+// chain of the variables being expanded, it is used to detect reference cycles.
+func (l *loader) expand(value string, stack []string) (string, error) {
+	var err error
+	mapping := func(ref string) string {
+		if err != nil {
+			return ""
+		}
 
-	mapping := func(key string) string {
-		v, _ := l.provider.LookupEnv(key)
+		for _, key := range stack {
+			if key == ref {
+				err = fmt.Errorf("env: expansion cycle: %s -> %s", strings.Join(stack, " -> "), ref)
+				return ""
+			}
+		}
+
+		if v, ok := l.expanded[ref]; ok {
+			return v
+		}
+
+		raw, _ := l.provider.LookupEnv(ref)
+		var v string
+		if v, err = l.expand(raw, append(stack[:len(stack):len(stack)], ref)); err != nil {
+			return ""
+		}
+
+		if l.expanded == nil {
+			l.expanded = make(map[string]string)
+		}
+		l.expanded[ref] = v
 		return v
 	}
 
-	return os.Expand(value, mapping), true
+	var expanded string
+	switch l.expansionStyle {
+	case ExpandWindows:
+		expanded = expandPercent(value, mapping, false)
+	case ExpandBoth:
+		expanded = expandPercent(value, mapping, true)
+	default:
+		expanded = os.Expand(value, mapping)
+	}
+	return expanded, err
+}
+
+// expandPercent replaces %VAR% in s based on the mapping function, like
+// [os.Expand] does it for $VAR. %% is replaced with a single %. If dollar is
+// true, $VAR and ${VAR} in the rest of s are replaced using [os.Expand] as well.
+func expandPercent(s string, mapping func(string) string, dollar bool) string {
+	var sb strings.Builder
+	literal := func(text string) {
+		if dollar {
+			text = os.Expand(text, mapping)
+		}
+		sb.WriteString(text)
+	}
+
+	for {
+		i := strings.IndexByte(s, '%')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i+1:], '%')
+		if j < 0 {
+			break
+		}
+		j += i + 1
+
+		switch name := s[i+1 : j]; {
+		case name == "":
+			literal(s[:i])
+			sb.WriteByte('%')
+		case isPercentName(name):
+			literal(s[:i])
+			sb.WriteString(mapping(name))
+		default:
+			// not a reference, the closing % may start the next one.
+			literal(s[:i+1])
+			s = s[i+1:]
+			continue
+		}
+		s = s[j+1:]
+	}
+
+	literal(s)
+	return sb.String()
+}
+
+// isPercentName reports whether name is a valid name in the %VAR% syntax.
+func isPercentName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
 }
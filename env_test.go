@@ -2,9 +2,12 @@ package env_test
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -36,6 +39,20 @@ func TestLoadFrom(t *testing.T) {
 		assert.IsErr[E](t, err, env.ErrEmptyTagName)
 	})
 
+	t.Run("duplicate name", func(t *testing.T) {
+		var cfg struct {
+			HTTP struct {
+				Port int `env:"PORT"`
+			}
+			GRPC struct {
+				Port int `env:"PORT"`
+			}
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.IsErr[E](t, err, env.ErrDuplicateName)
+		assert.ErrorContains[E](t, err, `"PORT": HTTP.Port and GRPC.Port`)
+	})
+
 	t.Run("unsupported type", func(t *testing.T) {
 		m := env.Map{"PORT": "8080"}
 
@@ -58,8 +75,8 @@ func TestLoadFrom(t *testing.T) {
 		}
 		err := env.LoadFrom(m, &cfg)
 		assert.NoErr[F](t, err)
-		assert.Equal[E](t, cfg.unexported, "")
-		assert.Equal[E](t, cfg.MissingTag, "")
+		assert.Zero[E](t, cfg.unexported)
+		assert.Zero[E](t, cfg.MissingTag)
 	})
 
 	t.Run("default values", func(t *testing.T) {
@@ -125,6 +142,79 @@ func TestLoadFrom(t *testing.T) {
 		assert.Equal[E](t, cfg.Addr, "localhost:8080")
 	})
 
+	t.Run("expand memoized", func(t *testing.T) {
+		m := env.Map{
+			"SCHEME":   "https",
+			"HOST":     "localhost",
+			"BASE_URL": "${SCHEME}://${HOST}", // expanded recursively.
+			"API_URL":  "${BASE_URL}/api",
+			"WEB_URL":  "${BASE_URL}/web",
+		}
+
+		lookups := make(map[string]int)
+		p := env.ProviderFunc(func(key string) (string, bool) {
+			lookups[key]++
+			return m.LookupEnv(key)
+		})
+
+		var cfg struct {
+			API string `env:"API_URL,expand"`
+			Web string `env:"WEB_URL,expand"`
+		}
+		err := env.LoadFrom(p, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.API, "https://localhost/api")
+		assert.Equal[E](t, cfg.Web, "https://localhost/web")
+		assert.Equal[E](t, lookups, map[string]int{"API_URL": 1, "WEB_URL": 1, "BASE_URL": 1, "SCHEME": 1, "HOST": 1})
+	})
+
+	t.Run("expand cycle", func(t *testing.T) {
+		m := env.Map{
+			"A": "${B}",
+			"B": "x${C}",
+			"C": "$A",
+		}
+
+		var cfg struct {
+			A string `env:"A,expand"`
+		}
+		err := env.LoadFrom(m, &cfg)
+		assert.ErrorContains[E](t, err, "expansion cycle: A -> B -> C -> A")
+
+		_, err = env.Plan(m, &cfg)
+		assert.ErrorContains[E](t, err, "expansion cycle")
+	})
+
+	t.Run("with expansion style", func(t *testing.T) {
+		m := env.Map{
+			"HOST":     "localhost",
+			"PORT":     "8080",
+			"BASE_URL": "http://%HOST%:$PORT",
+			"URL":      "%BASE_URL%/api?ratio=100%%&q=%not a var%",
+			"A":        "%B%",
+			"B":        "%A%",
+		}
+
+		test := func(style env.ExpansionStyle, want string) {
+			var cfg struct {
+				URL string `env:"URL,expand"`
+			}
+			err := env.LoadFrom(m, &cfg, env.WithExpansionStyle(style))
+			assert.NoErr[F](t, err)
+			assert.Equal[E](t, cfg.URL, want)
+		}
+
+		test(env.ExpandUnix, "%BASE_URL%/api?ratio=100%%&q=%not a var%")
+		test(env.ExpandWindows, "http://localhost:$PORT/api?ratio=100%&q=%not a var%")
+		test(env.ExpandBoth, "http://localhost:8080/api?ratio=100%&q=%not a var%")
+
+		var cfg struct {
+			A string `env:"A,expand"`
+		}
+		err := env.LoadFrom(m, &cfg, env.WithExpansionStyle(env.ExpandWindows))
+		assert.ErrorContains[E](t, err, "expansion cycle: A -> B -> A")
+	})
+
 	t.Run("invalid tag option", func(t *testing.T) {
 		var cfg struct {
 			HTTP struct {
@@ -169,6 +259,217 @@ func TestLoadFrom(t *testing.T) {
 		assert.Equal[E](t, notSetErr.Names, []string{"HOST"})
 	})
 
+	t.Run("with sources", func(t *testing.T) {
+		p := env.Multi(
+			env.Named("first", env.Map{"HOST": "localhost"}),
+			env.Map{"PORT": "8080"},
+		)
+
+		var cfg struct {
+			Host    string `env:"HOST"`
+			Port    int    `env:"PORT"`
+			Timeout int    `env:"TIMEOUT" default:"10"`
+		}
+		sources := make(map[string]string)
+		err := env.LoadFrom(p, &cfg, env.WithSources(sources))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, sources, map[string]string{
+			"HOST":    "first",
+			"PORT":    "env",
+			"TIMEOUT": "default",
+		})
+	})
+
+	t.Run("with groups", func(t *testing.T) {
+		m := env.Map{
+			"DB_HOST":   "localhost",
+			"DB_PORT":   "5432",
+			"HTTP_PORT": "8080",
+			"DEBUG":     "true",
+		}
+
+		var cfg struct {
+			DB struct {
+				Host string `env:"DB_HOST"`
+				Port int    `env:"DB_PORT"`
+			} `group:"db"`
+			HTTP struct {
+				Port int `env:"HTTP_PORT"`
+			}
+			Debug bool `env:"DEBUG" group:"db"`
+		}
+		err := env.LoadFrom(m, &cfg, env.WithGroups("db"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.DB.Host, "localhost")
+		assert.Equal[E](t, cfg.DB.Port, 5432)
+		assert.Zero[E](t, cfg.HTTP.Port)
+		assert.Equal[E](t, cfg.Debug, true)
+	})
+
+	t.Run("with strict prefix", func(t *testing.T) {
+		var unknownErr *env.UnknownError
+
+		m := env.Map{
+			"APP_TIMEOUT":  "1s",
+			"APP_TIMEOUTT": "2s", // a typo.
+			"APP_RETRIES":  "3",
+			"HOME":         "/home/user",
+		}
+
+		var cfg struct {
+			Timeout time.Duration `env:"TIMEOUT"`
+		}
+		err := env.LoadFrom(m, &cfg, env.WithPrefix("APP_"), env.WithStrictPrefix("APP_"))
+		assert.AsErr[F](t, err, &unknownErr)
+		assert.Equal[E](t, unknownErr.Names, []string{"APP_RETRIES", "APP_TIMEOUTT"})
+
+		// more coverage!
+		_ = unknownErr.Error()
+	})
+
+	t.Run("with unused report", func(t *testing.T) {
+		m := env.Map{
+			"APP_HOST":   "localhost",
+			"APP_PORT":   "8080",
+			"APP_ADDR":   "${APP_HOST}:${APP_PORT}",
+			"APP_LEGACY": "true",
+			"HOME":       "/home/user",
+		}
+
+		var cfg struct {
+			Addr string `env:"APP_ADDR,expand"`
+		}
+		var unused []string
+		err := env.LoadFrom(m, &cfg, env.WithUnusedReport("APP_", &unused))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, unused, []string{"APP_LEGACY"})
+	})
+
+	t.Run("deprecated names", func(t *testing.T) {
+		m := env.Map{
+			"DBHOST":   "localhost", // the second deprecated name.
+			"PORT":     "8080",
+			"OLD_PORT": "8081", // must be ignored since PORT is set.
+		}
+
+		var cfg struct {
+			Host string `env:"DB_HOST,required" deprecated:"DATABASE_HOST,DBHOST"`
+			Port int    `env:"PORT" deprecated:"OLD_PORT"`
+		}
+		err := env.LoadFrom(m, &cfg, env.WithStrictPrefix(""))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Host, "localhost")
+		assert.Equal[E](t, cfg.Port, 8080)
+	})
+
+	t.Run("empty deprecated name", func(t *testing.T) {
+		var cfg struct {
+			Host string `env:"HOST" deprecated:""`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.IsErr[E](t, err, env.ErrEmptyTagName)
+	})
+
+	t.Run("with warnings", func(t *testing.T) {
+		m := env.Map{
+			"OLD_HOST": "localhost",
+			"USER":     " admin ",
+		}
+
+		var cfg struct {
+			Host string `env:"HOST" deprecated:"OLD_HOST"`
+			User string `env:"USER"`
+		}
+		var warnings []env.Warning
+		err := env.LoadFrom(m, &cfg, env.WithWarnings(&warnings))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, warnings, []env.Warning{
+			{Name: "HOST", Message: "OLD_HOST is deprecated, use HOST instead"},
+			{Name: "USER", Message: "the value of USER has leading or trailing whitespace"},
+		})
+		assert.Equal[E](t, warnings[0].String(), "env: HOST: OLD_HOST is deprecated, use HOST instead")
+	})
+
+	t.Run("with concurrency", func(t *testing.T) {
+		m := env.Map{
+			"HOST":     "localhost",
+			"OLD_PORT": "8080",
+			"ADDR":     "${HOST}:${OLD_PORT}",
+			"USER":     " admin ",
+		}
+
+		// a slow provider that records the maximum number of concurrent lookups.
+		var inflight, maxInflight int32
+		p := env.ProviderFunc(func(key string) (string, bool) {
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInflight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return m.LookupEnv(key)
+		})
+
+		var cfg struct {
+			Host    string `env:"HOST"`
+			Port    int    `env:"PORT" deprecated:"OLD_PORT"`
+			Addr    string `env:"ADDR,expand"`
+			User    string `env:"USER"`
+			Timeout int    `env:"TIMEOUT" default:"5"`
+		}
+		sources := make(map[string]string)
+		var warnings []env.Warning
+		err := env.LoadFrom(p, &cfg, env.WithConcurrency(3), env.WithSources(sources), env.WithWarnings(&warnings))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Host, "localhost")
+		assert.Equal[E](t, cfg.Port, 8080)
+		assert.Equal[E](t, cfg.Addr, "localhost:8080")
+		assert.Equal[E](t, cfg.User, " admin ")
+		assert.Equal[E](t, cfg.Timeout, 5)
+		assert.Equal[E](t, sources["TIMEOUT"], "default")
+		assert.Len[E](t, warnings, 2)
+
+		max := atomic.LoadInt32(&maxInflight)
+		assert.Greater[E](t, max, 1)
+		assert.Less[E](t, max, 4)
+	})
+
+	t.Run("with error formatter", func(t *testing.T) {
+		formatter := env.WithErrorFormatter(func(err error) string {
+			var notSetErr *env.NotSetError
+			if errors.As(err, &notSetErr) {
+				return fmt.Sprintf("missing %v, see https://wiki.example.com/config", notSetErr.Names)
+			}
+			return err.Error() + " (see https://wiki.example.com/config)"
+		})
+
+		var cfg struct {
+			Host string `env:"HOST,required"`
+			Port int    `env:"PORT"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg, formatter)
+		var notSetErr *env.NotSetError
+		assert.AsErr[F](t, err, &notSetErr)
+		assert.Equal[E](t, err.Error(), "missing [HOST], see https://wiki.example.com/config")
+
+		err = env.LoadFrom(env.Map{"HOST": "localhost", "PORT": "http"}, &cfg, formatter)
+		var parseErr *env.ParseError
+		assert.AsErr[F](t, err, &parseErr)
+		assert.IsErr[E](t, err, strconv.ErrSyntax)
+		assert.Equal[E](t, err.Error(), `env: parsing PORT: parsing int: strconv.ParseInt: parsing "http": invalid syntax (see https://wiki.example.com/config)`)
+
+		var lazy struct {
+			Token env.Lazy[string] `env:"TOKEN,required,lazy"`
+		}
+		err = env.LoadFrom(env.Map{}, &lazy, formatter)
+		assert.NoErr[F](t, err)
+		_, err = lazy.Token.Get()
+		assert.Equal[E](t, err.Error(), "missing [TOKEN], see https://wiki.example.com/config")
+	})
+
 	t.Run("with usage on error", func(t *testing.T) {
 		// reset to the default usage after the test is finished.
 		usage := env.Usage
@@ -184,7 +485,7 @@ func TestLoadFrom(t *testing.T) {
 		}
 		err := env.LoadFrom(env.Map{}, &cfg, env.WithUsageOnError(io.Discard))
 		assert.AsErr[F](t, err, new(*env.NotSetError))
-		assert.Equal[E](t, called, true)
+		assert.True[E](t, called)
 	})
 
 	t.Run("all supported types", func(t *testing.T) {
@@ -284,6 +585,21 @@ func TestLoadFrom(t *testing.T) {
 		test("unmarshalers", cfg.IPs, []net.IP{net.IPv4zero, net.IPv4bcast})
 	})
 
+	t.Run("unset without default tag", func(t *testing.T) {
+		cfg := struct {
+			IP    net.IP   `env:"IP"`
+			IPs   []net.IP `env:"IPS"`
+			Ports []int    `env:"PORTS"`
+		}{
+			Ports: []int{80, 443}, // must be kept as is.
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Zero[E](t, cfg.IP)
+		assert.Zero[E](t, cfg.IPs)
+		assert.Equal[E](t, cfg.Ports, []int{80, 443})
+	})
+
 	t.Run("parsing errors", func(t *testing.T) {
 		test := func(name, envName string, checkErr func(error) bool) {
 			t.Run(name, func(t *testing.T) {
@@ -301,7 +617,11 @@ func TestLoadFrom(t *testing.T) {
 					Slice       []net.IP      `env:"SLICE"`
 				}
 				err := env.LoadFrom(m, &cfg)
-				assert.Equal[E](t, checkErr(err), true)
+				assert.True[E](t, checkErr(err))
+
+				var parseErr *env.ParseError
+				assert.AsErr[F](t, err, &parseErr)
+				assert.Equal[E](t, parseErr.Name, envName)
 			})
 		}
 
@@ -310,7 +630,7 @@ func TestLoadFrom(t *testing.T) {
 		}
 		isInvalidDuration := func(err error) bool {
 			// time.ParseDuration does not return any sentinel error :(
-			return errors.Unwrap(err).Error() == `time: invalid duration "-"`
+			return errors.Unwrap(errors.Unwrap(err)).Error() == `time: invalid duration "-"`
 		}
 		asParseError := func(err error) bool {
 			return errors.As(err, new(*net.ParseError))
@@ -325,3 +645,188 @@ func TestLoadFrom(t *testing.T) {
 		test("invalid slice", "SLICE", asParseError)
 	})
 }
+
+func TestLoadMap(t *testing.T) {
+	m := env.Map{
+		"APP_HOST": "localhost",
+		"APP_PORT": "8080",
+	}
+
+	t.Run("found keys only", func(t *testing.T) {
+		values, err := env.LoadMap(m, []string{"HOST", "PORT", "TIMEOUT"}, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, values, env.Map{"HOST": "localhost", "PORT": "8080"})
+	})
+
+	t.Run("with strict mode", func(t *testing.T) {
+		var notSetErr *env.NotSetError
+		_, err := env.LoadMap(m, []string{"HOST", "TIMEOUT"}, env.WithPrefix("APP_"), env.WithStrictMode())
+		assert.AsErr[F](t, err, &notSetErr)
+		assert.Equal[E](t, notSetErr.Names, []string{"APP_TIMEOUT"})
+	})
+}
+
+func TestExpandValue(t *testing.T) {
+	m := env.Map{
+		"HOST": "localhost",
+		"ADDR": "${HOST}:%PORT%",
+		"PORT": "8080",
+		"A":    "$B",
+		"B":    "$A",
+	}
+
+	value, err := env.ExpandValue(m, "URL", "http://$ADDR")
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, value, "http://localhost:%PORT%")
+
+	value, err = env.ExpandValue(m, "URL", "http://$ADDR", env.WithExpansionStyle(env.ExpandBoth))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, value, "http://localhost:8080")
+
+	_, err = env.ExpandValue(m, "A", "$B")
+	assert.ErrorContains[E](t, err, "expansion cycle: A -> B -> A")
+}
+
+func TestLoadAll(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT" default:"8080"`
+	}
+
+	m := env.Map{
+		"TENANT_A_HOST": "a.example.com",
+		"TENANT_B_HOST": "b.example.com",
+		"TENANT_B_PORT": "8081",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		cfgs, err := env.LoadAll[config](m, []string{"TENANT_A_", "TENANT_B_"})
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfgs, map[string]config{
+			"TENANT_A_": {Host: "a.example.com", Port: 8080},
+			"TENANT_B_": {Host: "b.example.com", Port: 8081},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var notSetErr *env.NotSetError
+		_, err := env.LoadAll[config](m, []string{"TENANT_A_", "TENANT_C_"})
+		assert.AsErr[F](t, err, &notSetErr)
+		assert.Equal[E](t, notSetErr.Names, []string{"TENANT_C_HOST"})
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.LoadAll[int](m, []string{"TENANT_A_"})
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+}
+
+// unmarshalerConfig is a config that implements [env.Unmarshaler].
+type unmarshalerConfig struct {
+	Addr string
+}
+
+func (c *unmarshalerConfig) UnmarshalEnv(p env.Provider) error {
+	host, _ := p.LookupEnv("HOST")
+	port, ok := p.LookupEnv("PORT")
+	if !ok {
+		return &env.NotSetError{Names: []string{"PORT"}}
+	}
+	c.Addr = host + ":" + port
+	return nil
+}
+
+func TestUnmarshaler(t *testing.T) {
+	var cfg unmarshalerConfig
+	err := env.LoadFrom(env.Map{"HOST": "localhost", "PORT": "8080"}, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Addr, "localhost:8080")
+
+	err = env.LoadFrom(env.Map{}, &cfg)
+	assert.AsErr[E](t, err, new(*env.NotSetError))
+}
+
+// cachedConfig is a named config type, so that its metadata is cached between
+// the calls.
+type cachedConfig struct {
+	Host string `env:"HOST" deprecated:"HOSTNAME"`
+	Port int    `env:"PORT"`
+}
+
+func TestMetadataCache(t *testing.T) {
+	t.Run("options applied per call", func(t *testing.T) {
+		cfg := cachedConfig{Port: 8080}
+		vars, err := env.Describe(&cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, vars[0].Name, "APP_HOST")
+		assert.Equal[E](t, vars[0].Deprecated, []string{"APP_HOSTNAME"})
+		assert.Equal[E](t, vars[1].Default, "8080")
+
+		cfg = cachedConfig{Port: 9090}
+		vars, err = env.Describe(&cfg, env.WithStrictMode())
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, vars[0].Name, "HOST")
+		assert.Equal[E](t, vars[0].Deprecated, []string{"HOSTNAME"})
+		assert.True[E](t, vars[1].Required)
+	})
+
+	t.Run("concurrent loads", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var cfg cachedConfig
+				err := env.LoadFrom(env.Map{"HOST": "localhost", "PORT": "8080"}, &cfg)
+				assert.NoErr[E](t, err)
+				assert.Equal[E](t, cfg, cachedConfig{Host: "localhost", Port: 8080})
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("cached error", func(t *testing.T) {
+		type config struct {
+			Port int `env:"PORT,invalid"`
+		}
+		for i := 0; i < 2; i++ {
+			err := env.LoadFrom(env.Map{}, new(config))
+			assert.IsErr[E](t, err, env.ErrInvalidTagOption)
+		}
+	})
+}
+
+// benchConfig is a config with the most common field types.
+type benchConfig struct {
+	Host    string        `env:"HOST"`
+	Port    int           `env:"PORT"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Name    string        `env:"NAME" default:"app"`
+	Retries int           `env:"RETRIES" default:"3"`
+}
+
+func BenchmarkLoadFrom(b *testing.B) {
+	m := env.Map{"HOST": "localhost", "PORT": "8080", "DEBUG": "true", "TIMEOUT": "5s"}
+
+	b.Run("present", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var cfg benchConfig
+			if err := env.LoadFrom(m, &cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("with prefix", func(b *testing.B) {
+		m := env.Map{"APP_HOST": "localhost", "APP_PORT": "8080", "APP_DEBUG": "true", "APP_TIMEOUT": "5s"}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var cfg benchConfig
+			if err := env.LoadFrom(m, &cfg, env.WithPrefix("APP_")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+// Package envtest provides helpers for testing code that uses the [env]
+// package.
+package envtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/junk1tm/env"
+)
+
+// TB is a tiny subset of [testing.TB] used by [envtest].
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	Cleanup(f func())
+}
+
+// Set sets the environment variable named by the key to the provided value and
+// restores its original state (including being unset) when the test and all
+// its subtests complete. Unlike [testing.T.Setenv], it does not forbid the use
+// of [testing.T.Parallel], so it is the caller's responsibility to avoid data
+// races.
+func Set(t TB, key, value string) {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("envtest: setting %s: %v", key, err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// Load loads the values from the provided [env.Map] into dst without touching
+// the process environment. It stops the test, if an error occurs. See
+// [env.LoadFrom] documentation for details.
+func Load(t TB, m env.Map, dst any, opts ...env.Option) {
+	t.Helper()
+	if err := env.LoadFrom(m, dst, opts...); err != nil {
+		t.Fatalf("envtest: loading env: %v", err)
+	}
+}
+
+// LoadFile loads the values from the dotenv fixture file named by name into
+// dst without touching the process environment. It stops the test, if an error
+// occurs. See [env.ParseDotenv] documentation for the supported syntax.
+func LoadFile(t TB, name string, dst any, opts ...env.Option) {
+	t.Helper()
+	if err := loadFile(name, dst, opts...); err != nil {
+		t.Fatalf("envtest: %s: %v", name, err)
+	}
+}
+
+// CheckFiles loads each dotenv fixture file matching the pattern (see
+// [filepath.Glob]), e.g. "testdata/*.env", into a new struct returned by newDst
+// and reports the files that fail to load. It is useful to keep example
+// environments verified in CI. If no files match the pattern, the test is
+// stopped.
+func CheckFiles(t TB, pattern string, newDst func() any, opts ...env.Option) {
+	t.Helper()
+
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatalf("envtest: matching %q: %v", pattern, err)
+		return
+	}
+	if len(names) == 0 {
+		t.Fatalf("envtest: no files match %q", pattern)
+		return
+	}
+
+	for _, name := range names {
+		if err := loadFile(name, newDst(), opts...); err != nil {
+			t.Errorf("envtest: %s: %v", name, err)
+		}
+	}
+}
+
+// AssertNotSet asserts that err is (or wraps) an [env.NotSetError] reporting
+// exactly the provided names, in any order:
+//
+//	err := env.LoadFrom(env.Map{}, &cfg)
+//	envtest.AssertNotSet(t, err, "DB_HOST", "DB_PORT")
+func AssertNotSet(t TB, err error, names ...string) {
+	t.Helper()
+	var notSetErr *env.NotSetError
+	if !errors.As(err, &notSetErr) {
+		t.Errorf("envtest: got error %v; want env.NotSetError", err)
+		return
+	}
+	if !sameNames(notSetErr.Names, names) {
+		t.Errorf("envtest: got %v not set; want %v", notSetErr.Names, names)
+	}
+}
+
+// AssertUnknown asserts that err is (or wraps) an [env.UnknownError] reporting
+// exactly the provided names, in any order.
+func AssertUnknown(t TB, err error, names ...string) {
+	t.Helper()
+	var unknownErr *env.UnknownError
+	if !errors.As(err, &unknownErr) {
+		t.Errorf("envtest: got error %v; want env.UnknownError", err)
+		return
+	}
+	if !sameNames(unknownErr.Names, names) {
+		t.Errorf("envtest: got %v unknown; want %v", unknownErr.Names, names)
+	}
+}
+
+// AssertParseError asserts that err is (or wraps) an [env.ParseError] for the
+// environment variable named by name:
+//
+//	err := env.LoadFrom(env.Map{"PORT": "-"}, &cfg)
+//	envtest.AssertParseError(t, err, "PORT")
+func AssertParseError(t TB, err error, name string) {
+	t.Helper()
+	var parseErr *env.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("envtest: got error %v; want env.ParseError", err)
+		return
+	}
+	if parseErr.Name != name {
+		t.Errorf("envtest: got parsing error of %s; want %s", parseErr.Name, name)
+	}
+}
+
+// sameNames reports whether got and want contain the same names, regardless of
+// their order.
+func sameNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	got = append([]string(nil), got...)
+	want = append([]string(nil), want...)
+	sort.Strings(got)
+	sort.Strings(want)
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadFile loads the values from the dotenv file named by name into dst.
+func loadFile(name string, dst any, opts ...env.Option) error {
+	m, err := env.ReadDotenv(name)
+	if err != nil {
+		return err
+	}
+	return env.LoadFrom(m, dst, opts...)
+}
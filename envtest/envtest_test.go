@@ -0,0 +1,172 @@
+package envtest_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+	"github.com/junk1tm/env/envtest"
+)
+
+// fakeTB is a [envtest.TB] implementation that records its calls.
+type fakeTB struct {
+	failed   bool
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(string, ...any) { tb.failed = true }
+
+func (tb *fakeTB) Fatalf(string, ...any) { tb.failed = true }
+
+func (tb *fakeTB) Cleanup(f func()) { tb.cleanups = append(tb.cleanups, f) }
+
+func (tb *fakeTB) cleanup() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func TestSet(t *testing.T) {
+	const key = "ENVTEST_SET"
+
+	t.Run("previously unset", func(t *testing.T) {
+		tb := new(fakeTB)
+		envtest.Set(tb, key, "1")
+		assert.Equal[E](t, os.Getenv(key), "1")
+
+		tb.cleanup()
+		_, ok := os.LookupEnv(key)
+		assert.False[E](t, ok)
+	})
+
+	t.Run("previously set", func(t *testing.T) {
+		t.Setenv(key, "0")
+
+		tb := new(fakeTB)
+		envtest.Set(tb, key, "1")
+		assert.Equal[E](t, os.Getenv(key), "1")
+
+		tb.cleanup()
+		assert.Equal[E](t, os.Getenv(key), "0")
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var cfg struct {
+			Port int `env:"PORT"`
+		}
+		tb := new(fakeTB)
+		envtest.Load(tb, env.Map{"PORT": "8080"}, &cfg)
+		assert.False[E](t, tb.failed)
+		assert.Equal[E](t, cfg.Port, 8080)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var cfg struct {
+			Port int `env:"PORT,required"`
+		}
+		tb := new(fakeTB)
+		envtest.Load(tb, env.Map{}, &cfg)
+		assert.True[E](t, tb.failed)
+	})
+}
+
+type fixtureConfig struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT" default:"8080"`
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var cfg fixtureConfig
+		tb := new(fakeTB)
+		envtest.LoadFile(tb, "testdata/valid/local.env", &cfg)
+		assert.False[E](t, tb.failed)
+		assert.Equal[E](t, cfg.Host, "localhost")
+		assert.Equal[E](t, cfg.Port, 8000)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		var cfg fixtureConfig
+		tb := new(fakeTB)
+		envtest.LoadFile(tb, "testdata/missing.env", &cfg)
+		assert.True[E](t, tb.failed)
+	})
+}
+
+func TestCheckFiles(t *testing.T) {
+	newDst := func() any { return new(fixtureConfig) }
+
+	test := func(name, pattern string, wantFailed bool) {
+		t.Run(name, func(t *testing.T) {
+			tb := new(fakeTB)
+			envtest.CheckFiles(tb, pattern, newDst)
+			assert.Equal[E](t, tb.failed, wantFailed)
+		})
+	}
+
+	test("valid fixtures", "testdata/valid/*.env", false)
+	test("invalid fixtures", "testdata/invalid/*.env", true)
+	test("no matches", "testdata/*.missing", true)
+}
+
+func TestAssertNotSet(t *testing.T) {
+	err := &env.NotSetError{Names: []string{"DB_HOST", "DB_PORT"}}
+
+	test := func(name string, err error, names []string, wantFailed bool) {
+		t.Run(name, func(t *testing.T) {
+			tb := new(fakeTB)
+			envtest.AssertNotSet(tb, err, names...)
+			assert.Equal[E](t, tb.failed, wantFailed)
+		})
+	}
+
+	test("match", err, []string{"DB_HOST", "DB_PORT"}, false)
+	test("any order", err, []string{"DB_PORT", "DB_HOST"}, false)
+	test("wrapped", fmt.Errorf("loading config: %w", err), []string{"DB_HOST", "DB_PORT"}, false)
+	test("different names", err, []string{"DB_HOST"}, true)
+	test("other error", errors.New("oops"), []string{"DB_HOST"}, true)
+	test("no error", nil, nil, true)
+}
+
+func TestAssertUnknown(t *testing.T) {
+	err := &env.UnknownError{Names: []string{"APP_RETRIES"}}
+
+	test := func(name string, err error, names []string, wantFailed bool) {
+		t.Run(name, func(t *testing.T) {
+			tb := new(fakeTB)
+			envtest.AssertUnknown(tb, err, names...)
+			assert.Equal[E](t, tb.failed, wantFailed)
+		})
+	}
+
+	test("match", err, []string{"APP_RETRIES"}, false)
+	test("different names", err, []string{"APP_TIMEOUT"}, true)
+	test("other error", &env.NotSetError{Names: []string{"APP_RETRIES"}}, []string{"APP_RETRIES"}, true)
+}
+
+func TestAssertParseError(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	err := env.LoadFrom(env.Map{"PORT": "-"}, &cfg)
+
+	test := func(name string, err error, varName string, wantFailed bool) {
+		t.Run(name, func(t *testing.T) {
+			tb := new(fakeTB)
+			envtest.AssertParseError(tb, err, varName)
+			assert.Equal[E](t, tb.failed, wantFailed)
+		})
+	}
+
+	test("match", err, "PORT", false)
+	test("different name", err, "HOST", true)
+	test("no error", nil, "PORT", true)
+}
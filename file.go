@@ -0,0 +1,70 @@
+package env
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+var (
+	readerType     = reflect.TypeOf(new(io.Reader)).Elem()
+	readCloserType = reflect.TypeOf(new(io.ReadCloser)).Elem()
+)
+
+// setFile sets v's underlying value to a reader of the file named by name,
+// which is opened on the first read. If name is empty, v is set to nil.
+func setFile(v reflect.Value, name string) error {
+	if name == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	v.Set(reflect.ValueOf(&fileReader{name: name}))
+	return nil
+}
+
+// checkFile reports whether the file named by name exists, the same way it
+// would be checked by the first read of the reader set by setFile. An empty
+// name is valid, it sets the reader to nil.
+func checkFile(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := os.Stat(name)
+	return err
+}
+
+// fileReader is an [io.ReadCloser] that opens the file on the first read, so
+// that large files (e.g. certificates or seed data) are neither opened nor
+// read into memory by [Load]. It is not safe for concurrent use.
+type fileReader struct {
+	name string
+	file *os.File
+	err  error // the error occurred while opening the file or os.ErrClosed.
+}
+
+// Read implements the [io.Reader] interface. The error of opening the file, if
+// any, is returned by the first and all subsequent calls.
+func (r *fileReader) Read(p []byte) (int, error) {
+	if r.file == nil && r.err == nil {
+		r.file, r.err = os.Open(r.name)
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.file.Read(p)
+}
+
+// Close implements the [io.Closer] interface. If the file has not been opened
+// yet, it never will be.
+func (r *fileReader) Close() error {
+	if r.file == nil {
+		if r.err == nil {
+			r.err = os.ErrClosed
+		}
+		return nil
+	}
+	return r.file.Close()
+}
+
+// String returns the name of the file.
+func (r *fileReader) String() string { return r.name }
@@ -0,0 +1,84 @@
+package env_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestFileOption(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cert.pem")
+
+	var cfg struct {
+		Cert io.ReadCloser `env:"CERT_FILE,file"`
+		Seed io.Reader     `env:"SEED_FILE,file"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(env.Map{"CERT_FILE": name}, &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, sources, map[string]string{"CERT_FILE": "env", "SEED_FILE": "default"})
+	assert.True[E](t, cfg.Seed == nil)
+
+	// the file is created after Load to ensure it is opened lazily.
+	err = os.WriteFile(name, []byte("-----BEGIN CERTIFICATE-----"), 0o600)
+	assert.NoErr[F](t, err)
+
+	data, err := io.ReadAll(cfg.Cert)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, string(data), "-----BEGIN CERTIFICATE-----")
+	assert.NoErr[F](t, cfg.Cert.Close())
+
+	t.Run("not exist", func(t *testing.T) {
+		var cfg struct {
+			Cert io.Reader `env:"CERT_FILE,file"`
+		}
+		err := env.LoadFrom(env.Map{"CERT_FILE": filepath.Join(t.TempDir(), "missing")}, &cfg)
+		assert.NoErr[F](t, err)
+
+		_, err = io.ReadAll(cfg.Cert)
+		assert.IsErr[E](t, err, fs.ErrNotExist)
+	})
+
+	t.Run("closed before read", func(t *testing.T) {
+		var cfg struct {
+			Cert io.ReadCloser `env:"CERT_FILE,file"`
+		}
+		err := env.LoadFrom(env.Map{"CERT_FILE": name}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.NoErr[F](t, cfg.Cert.Close())
+
+		_, err = cfg.Cert.Read(make([]byte, 1))
+		assert.IsErr[E](t, err, os.ErrClosed)
+	})
+
+	t.Run("ignored by helpers", func(t *testing.T) {
+		m, err := env.Marshal(&cfg)
+		assert.NoErr[F](t, err)
+		assert.Len[E](t, m, 0)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var cfg struct {
+			Cert string `env:"CERT_FILE,file"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.True[E](t, errors.Is(err, env.ErrInvalidTagOption))
+		assert.ErrorContains[E](t, err, "CERT_FILE must be of type io.Reader or io.ReadCloser")
+	})
+
+	t.Run("missing option", func(t *testing.T) {
+		var cfg struct {
+			Cert io.Reader `env:"CERT_FILE"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.True[E](t, errors.Is(err, env.ErrInvalidTagOption))
+		assert.ErrorContains[E](t, err, `CERT_FILE of type io.Reader requires the "file" option`)
+	})
+}
@@ -0,0 +1,291 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags defines a flag in the provided [flag.FlagSet] for each
+// environment variable parsed from the fields of dst, so that every variable
+// can also be overridden from the command line. The name of a flag is derived
+// from the name of the variable (without the prefix set by [WithPrefix]) by
+// converting it to lowercase and replacing underscores with dashes, e.g.
+// DB_HOST becomes -db-host. The current values of the struct fields are used as
+// the default values of the flags, so environment variables should be loaded
+// first:
+//
+//	if err := env.Load(&cfg); err != nil {
+//		// handle error
+//	}
+//	if err := env.RegisterFlags(flag.CommandLine, &cfg); err != nil {
+//		// handle error
+//	}
+//	flag.Parse()
+//
+// dst must be a non-nil struct pointer, otherwise RegisterFlags returns
+// [ErrInvalidArgument].
+func RegisterFlags(fs *flag.FlagSet, dst any, opts ...Option) error {
+	vars, sep, err := flagVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		fs.Var(&flagValue{field: v.field, sep: sep}, v.flagName, v.usage)
+	}
+
+	return nil
+}
+
+// AppendFlagUsage replaces the Usage function of the provided [flag.FlagSet]
+// with the one that also documents the environment variables parsed from the
+// fields of dst, so that `myapp -h` shows both flags and environment variables
+// in one place. The usage message of the flags is printed first, using the
+// previous Usage function (or the default one, if it is nil), then the message
+// of the variables is printed using the [Usage] function, so the usage options
+// (e.g. [WithUsageOrder]) are respected. See [Describe] for how dst is parsed.
+// The default values are captured when AppendFlagUsage is called, so
+// environment variables should be loaded first:
+//
+//	if err := env.Load(&cfg); err != nil {
+//		// handle error
+//	}
+//	if err := env.AppendFlagUsage(flag.CommandLine, &cfg); err != nil {
+//		// handle error
+//	}
+//	flag.Parse()
+func AppendFlagUsage(fs *flag.FlagSet, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+	vars = newLoader(nil, opts...).usageVars(vars)
+
+	prev := fs.Usage
+	fs.Usage = func() {
+		if prev != nil {
+			prev()
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+			fs.PrintDefaults()
+		}
+		fmt.Fprintln(fs.Output())
+		Usage(fs.Output(), vars)
+	}
+
+	return nil
+}
+
+// flagVar is an environment variable that can be registered as a flag.
+type flagVar struct {
+	Var
+	flagName string
+	usage    string
+}
+
+// flagVars parses environment variables from the fields of dst and derives the
+// names and usage messages of the corresponding flags. It also returns the
+// slice separator configured by the options.
+func flagVars(dst any, opts ...Option) ([]flagVar, string, error) {
+	rv := reflect.ValueOf(dst)
+	if !structPtr(rv) {
+		return nil, "", ErrInvalidArgument
+	}
+
+	l := newLoader(nil, opts...)
+
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+	vars = filterVars(vars, func(v Var) bool { return !v.opaque() })
+
+	fvs := make([]flagVar, len(vars))
+	for i, v := range vars {
+		usage := "env " + v.Name
+		if v.Desc != "" {
+			usage = v.Desc + " (" + usage + ")"
+		}
+		fvs[i] = flagVar{
+			Var:      v,
+			flagName: flagName(strings.TrimPrefix(v.Name, l.prefix)),
+			usage:    usage,
+		}
+	}
+
+	return fvs, l.sliceSep, nil
+}
+
+// flagName converts the name of an environment variable to a flag name, e.g.
+// DB_HOST to db-host.
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// flagValue is a [flag.Value] implementation that sets the struct field.
+type flagValue struct {
+	field reflect.Value
+	sep   string
+}
+
+// String implements the [flag.Value] interface.
+func (f *flagValue) String() string {
+	if f == nil || !f.field.IsValid() {
+		// the flag package may call String on a zero value.
+		return ""
+	}
+	s, _ := formatValue(f.field, f.sep)
+	return s
+}
+
+// Set implements the [flag.Value] interface.
+func (f *flagValue) Set(s string) error {
+	if kindOf(f.field, reflect.Slice) && !implements(f.field, unmarshalerIface) {
+		return setSlice(f.field, strings.Split(s, f.sep))
+	}
+	return setValue(f.field, s)
+}
+
+// IsBoolFlag allows boolean flags to be specified without a value, e.g.
+// -debug instead of -debug=true.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.IsValid() && kindOf(f.field, reflect.Bool)
+}
+
+// PFlagSet is a tiny subset of the [github.com/spf13/pflag] FlagSet API used by
+// [RegisterPFlags] and [PFlagProvider]. It allows cobra-based CLIs to use this
+// package without making it depend on pflag.
+type PFlagSet interface {
+	// VarP defines a flag with the specified name, shorthand, and usage
+	// string.
+	VarP(value PFlagValue, name, shorthand, usage string)
+	// SetNoOptDefVal sets the value of the flag used when it is specified
+	// without one, i.e. pflag.Flag.NoOptDefVal. pflag requires it to allow
+	// boolean flags like --debug instead of --debug=true.
+	SetNoOptDefVal(name, value string)
+}
+
+// PFlagValue is a tiny subset of the [github.com/spf13/pflag] Value interface.
+// It is a [flag.Value] with the Type method.
+type PFlagValue interface {
+	flag.Value
+	// Type returns the name of the value's type, used in help messages.
+	Type() string
+}
+
+// RegisterPFlags is like [RegisterFlags], but defines the flags in a pflag
+// FlagSet, e.g. the one returned by cobra.Command.Flags(). The parsed flag
+// values are written directly to the struct fields, overriding the values
+// loaded from the environment. To load them with the rest of the environment
+// instead (e.g. to respect [WithSources]), use [PFlagProvider]. pflag's VarP
+// method accepts the pflag.Value interface, so the FlagSet has to be adapted:
+//
+//	type pflagSet struct{ *pflag.FlagSet }
+//
+//	func (fs pflagSet) VarP(v env.PFlagValue, name, shorthand, usage string) {
+//		fs.FlagSet.VarP(v, name, shorthand, usage)
+//	}
+//
+//	func (fs pflagSet) SetNoOptDefVal(name, value string) {
+//		fs.FlagSet.Lookup(name).NoOptDefVal = value
+//	}
+//
+// Boolean flags can be specified without a value, e.g. --debug, since their
+// NoOptDefVal is set to "true".
+func RegisterPFlags(fs PFlagSet, dst any, opts ...Option) error {
+	vars, sep, err := flagVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		registerPFlag(fs, &pflagValue{flagValue{field: v.field, sep: sep}}, v)
+	}
+
+	return nil
+}
+
+// PFlagProvider is like [RegisterPFlags], but the parsed flag values are not
+// written to the struct fields. Instead, the returned [Provider] looks up the
+// values of the flags set on the command line by the names of the
+// corresponding environment variables (including the prefix set by
+// [WithPrefix]), so that flags take part in the usual precedence, e.g.:
+//
+//	flags, err := env.PFlagProvider(pflagSet{cmd.Flags()}, &cfg)
+//	if err != nil {
+//		// handle error
+//	}
+//	if err := cmd.ParseFlags(os.Args[1:]); err != nil {
+//		// handle error
+//	}
+//	if err := env.LoadFrom(env.Multi(flags, env.OS), &cfg); err != nil {
+//		// handle error
+//	}
+//
+// The values are validated when the flags are parsed. The current values of
+// the struct fields are only used as the default values shown in help
+// messages. Its name is "flags", see [WithSources] for details.
+func PFlagProvider(fs PFlagSet, dst any, opts ...Option) (Provider, error) {
+	vars, sep, err := flagVars(dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(Map)
+	for _, v := range vars {
+		// the values are parsed into a copy to validate them.
+		field := reflect.New(v.Type).Elem()
+		field.Set(v.field)
+		registerPFlag(fs, &recordingPFlagValue{
+			pflagValue: pflagValue{flagValue{field: field, sep: sep}},
+			name:       v.Name,
+			values:     m,
+		}, v)
+	}
+
+	return Named("flags", m), nil
+}
+
+// registerPFlag defines a flag for v in fs, allowing boolean flags to be
+// specified without a value.
+func registerPFlag(fs PFlagSet, value PFlagValue, v flagVar) {
+	fs.VarP(value, v.flagName, "", v.usage)
+	if kindOf(v.field, reflect.Bool) {
+		fs.SetNoOptDefVal(v.flagName, "true")
+	}
+}
+
+// pflagValue is a [PFlagValue] implementation that sets the struct field.
+type pflagValue struct{ flagValue }
+
+// Type implements the [PFlagValue] interface. pflag treats flags of the "bool"
+// type as boolean ones.
+func (p *pflagValue) Type() string {
+	if kindOf(p.field, reflect.Bool) {
+		return "bool"
+	}
+	return p.field.Type().String()
+}
+
+// recordingPFlagValue is a [PFlagValue] implementation that records the values
+// of the flags set on the command line, see [PFlagProvider].
+type recordingPFlagValue struct {
+	pflagValue
+	name   string // the name of the environment variable.
+	values Map
+}
+
+// Set implements the [PFlagValue] interface.
+func (r *recordingPFlagValue) Set(s string) error {
+	if err := r.pflagValue.Set(s); err != nil {
+		return err
+	}
+	r.values[r.name] = s
+	return nil
+}
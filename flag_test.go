@@ -0,0 +1,228 @@
+package env_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		err := env.RegisterFlags(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		m := env.Map{
+			"APP_DB_HOST":  "localhost",
+			"APP_DB_PORT":  "5432",
+			"APP_TIMEOUTS": "1s 2s",
+		}
+
+		var cfg struct {
+			Host     string          `env:"DB_HOST" desc:"database host"`
+			Port     int             `env:"DB_PORT"`
+			Debug    bool            `env:"DEBUG"`
+			Timeouts []time.Duration `env:"TIMEOUTS"`
+		}
+		err := env.LoadFrom(m, &cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		err = env.RegisterFlags(fs, &cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+
+		f := fs.Lookup("db-host")
+		assert.NotNil[F](t, f)
+		assert.Equal[E](t, f.DefValue, "localhost")
+		assert.Equal[E](t, f.Usage, "database host (env APP_DB_HOST)")
+
+		err = fs.Parse([]string{"-db-port=5433", "-debug", "-timeouts=3s"})
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Host, "localhost")
+		assert.Equal[E](t, cfg.Port, 5433)
+		assert.Equal[E](t, cfg.Debug, true)
+		assert.Equal[E](t, cfg.Timeouts, []time.Duration{3 * time.Second})
+
+		// flag.PrintDefaults calls String on zero values.
+		fs.PrintDefaults()
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var cfg struct {
+			Port int `env:"PORT"`
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		err := env.RegisterFlags(fs, &cfg)
+		assert.NoErr[F](t, err)
+
+		err = fs.Parse([]string{"-port=foo"})
+		assert.ErrorContains[E](t, err, "invalid value \"foo\" for flag -port")
+	})
+}
+
+// fakePFlagSet is a [env.PFlagSet] implementation mimicking pflag's parsing:
+// unlike the flag package, it ignores IsBoolFlag, so a flag specified without
+// a value takes NoOptDefVal, if set, or the next argument otherwise.
+type fakePFlagSet struct {
+	values       map[string]env.PFlagValue
+	types        map[string]string
+	noOptDefVals map[string]string
+}
+
+func newFakePFlagSet() *fakePFlagSet {
+	return &fakePFlagSet{
+		values:       make(map[string]env.PFlagValue),
+		types:        make(map[string]string),
+		noOptDefVals: make(map[string]string),
+	}
+}
+
+func (fs *fakePFlagSet) VarP(v env.PFlagValue, name, _, _ string) {
+	fs.values[name] = v
+	fs.types[name] = v.Type()
+}
+
+func (fs *fakePFlagSet) SetNoOptDefVal(name, value string) { fs.noOptDefVals[name] = value }
+
+func (fs *fakePFlagSet) Parse(args []string) error {
+	for i := 0; i < len(args); i++ {
+		name, value, ok := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		v, found := fs.values[name]
+		if !found {
+			return fmt.Errorf("unknown flag: --%s", name)
+		}
+		switch {
+		case ok:
+		case fs.noOptDefVals[name] != "":
+			value = fs.noOptDefVals[name]
+		case i+1 < len(args):
+			i++
+			value = args[i]
+		default:
+			return fmt.Errorf("flag needs an argument: --%s", name)
+		}
+		if err := v.Set(value); err != nil {
+			return fmt.Errorf("invalid argument %q for --%s: %w", value, name, err)
+		}
+	}
+	return nil
+}
+
+func TestAppendFlagUsage(t *testing.T) {
+	var cfg struct {
+		Port int `env:"PORT" default:"8080" desc:"http server port"`
+	}
+
+	t.Run("default flag usage", func(t *testing.T) {
+		const usage = `Usage of app:
+  -v	verbose output
+
+Usage:
+  PORT  int  default 8080  http server port
+`
+		var buf bytes.Buffer
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(&buf)
+		fs.Bool("v", false, "verbose output")
+
+		err := env.AppendFlagUsage(fs, &cfg)
+		assert.NoErr[F](t, err)
+
+		err = fs.Parse([]string{"-h"})
+		assert.IsErr[E](t, err, flag.ErrHelp)
+		assert.Equal[E](t, buf.String(), usage)
+	})
+
+	t.Run("custom flag usage", func(t *testing.T) {
+		var buf bytes.Buffer
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(&buf)
+		fs.Usage = func() { buf.WriteString("usage: app [flags]\n") }
+
+		err := env.AppendFlagUsage(fs, &cfg)
+		assert.NoErr[F](t, err)
+
+		fs.Usage()
+		assert.Equal[E](t, buf.String(), "usage: app [flags]\n\nUsage:\n  PORT  int  default 8080  http server port\n")
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		err := env.AppendFlagUsage(flag.NewFlagSet("app", flag.ContinueOnError), nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+}
+
+func TestRegisterPFlags(t *testing.T) {
+	var cfg struct {
+		Port  int  `env:"PORT" default:"8080"`
+		Debug bool `env:"DEBUG"`
+	}
+	err := env.LoadFrom(env.Map{}, &cfg)
+	assert.NoErr[F](t, err)
+
+	fs := newFakePFlagSet()
+	err = env.RegisterPFlags(fs, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, fs.types, map[string]string{"port": "int", "debug": "bool"})
+	assert.Equal[E](t, fs.noOptDefVals, map[string]string{"debug": "true"})
+
+	err = fs.Parse([]string{"--port=8081", "--debug"})
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Port, 8081)
+	assert.Equal[E](t, cfg.Debug, true)
+}
+
+func TestPFlagProvider(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST" default:"localhost"`
+		Port  int    `env:"PORT" default:"8080"`
+		Debug bool   `env:"DEBUG"`
+	}
+
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.PFlagProvider(newFakePFlagSet(), nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		var cfg config
+		fs := newFakePFlagSet()
+		flags, err := env.PFlagProvider(fs, &cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+
+		err = fs.Parse([]string{"--port", "9090", "--debug"})
+		assert.NoErr[F](t, err)
+		assert.Zero[E](t, cfg.Port)
+
+		p := env.Multi(flags, env.Map{"APP_HOST": "example.com", "APP_PORT": "8081"})
+		sources := make(map[string]string)
+		err = env.LoadFrom(p, &cfg, env.WithPrefix("APP_"), env.WithSources(sources))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg, config{Host: "example.com", Port: 9090, Debug: true})
+		assert.Equal[E](t, sources, map[string]string{"APP_HOST": "env", "APP_PORT": "flags", "APP_DEBUG": "flags"})
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var cfg config
+		fs := newFakePFlagSet()
+		flags, err := env.PFlagProvider(fs, &cfg)
+		assert.NoErr[F](t, err)
+
+		err = fs.Parse([]string{"--port=http"})
+		assert.ErrorContains[E](t, err, `invalid argument "http" for --port`)
+
+		_, ok := flags.LookupEnv("PORT")
+		assert.False[E](t, ok)
+	})
+}
@@ -0,0 +1,577 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WriteMarkdown writes a Markdown table documenting the environment variables
+// parsed from the fields of the provided struct to w. The table contains the
+// name, type, whether the variable is required, its default value,
+// description, and example (parsed from the `example` tag), so it can be
+// pasted into a README or a docs site. See [Describe] for how dst is parsed.
+func WriteMarkdown(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Name | Type | Required | Default | Description | Example |\n")
+	sb.WriteString("|------|------|----------|---------|-------------|---------|\n")
+
+	for _, v := range vars {
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&sb, "| `%s` | `%s` | %s | %s | %s | %s |\n",
+			v.Name, v.Type, required, markdownCode(v.Default), markdownText(v.Desc), markdownCode(v.Example))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownCode formats s as an inline code span for a Markdown table cell, or
+// returns an empty string, if s is empty.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownText escapes s to be used in a Markdown table cell.
+func markdownText(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// WriteJSONSchema writes a JSON Schema (draft 2020-12) describing the
+// environment variables parsed from the fields of the provided struct to w, so
+// that deployment manifests can be validated before rollout. Each variable
+// becomes a property of the root object with its type, description, default
+// value, and example; the required variables are listed in `required`, and the
+// secret ones are marked as `writeOnly`. The Go type and the path of the field
+// (e.g. "DB.Port") and the deprecated names of the variable are reported via
+// the `x-go-type`, `x-go-field`, and `x-deprecated-names` extension keywords,
+// respectively.
+//
+// In addition to the tags supported by [Load], the following tags are used to
+// restrict the values of a variable in the schema (they are not enforced by
+// [Load]): `enum` for a comma-separated list of allowed values, and `min`/`max`
+// for the bounds of a numeric value. For slices, the restrictions apply to the
+// elements.
+//
+// See [Describe] for how dst is parsed.
+func WriteJSONSchema(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	l := newLoader(nil, opts...)
+	schema := jsonSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Type:       "object",
+		Properties: make(map[string]*jsonSchemaProperty, len(vars)),
+	}
+
+	for _, v := range vars {
+		prop, err := l.jsonSchemaProperty(v)
+		if err != nil {
+			return err
+		}
+		schema.Properties[v.Name] = prop
+		if v.Required {
+			schema.Required = append(schema.Required, v.Name)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+// WriteExample writes a `.env.example` file documenting the environment
+// variables parsed from the fields of the provided struct to w, so that it can
+// be generated from code instead of being maintained by hand. Each variable is
+// preceded by a comment with its description and is set to its default value,
+// or to its example (parsed from the `example` tag), if there is no default
+// one. The values of the variables marked as secret are always left empty. The
+// result can be read back with [ParseDotenv]. See [Describe] for how dst is
+// parsed.
+func WriteExample(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		var notes []string
+		if v.Required {
+			notes = append(notes, "required")
+		}
+		if v.Secret {
+			notes = append(notes, "secret")
+		}
+
+		switch comment := strings.Join(notes, ", "); {
+		case v.Desc != "" && comment != "":
+			fmt.Fprintf(&sb, "# %s (%s)\n", v.Desc, comment)
+		case v.Desc != "":
+			fmt.Fprintf(&sb, "# %s\n", v.Desc)
+		case comment != "":
+			fmt.Fprintf(&sb, "# %s\n", comment)
+		}
+
+		var value string
+		switch {
+		case v.Secret:
+		case v.Default != "":
+			value = v.Default
+		default:
+			value = v.Example
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", v.Name, quoteDotenv(value))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteCompose writes the `environment:` block of a Docker Compose service
+// documenting the environment variables parsed from the fields of the provided
+// struct to w, so that compose files can be kept in sync with code. Each
+// variable is interpolated from the host environment: the required variables
+// use the `${NAME:?...}` syntax, so that Docker Compose refuses to start the
+// service if they are not set, and the others fall back to their default
+// values using the `${NAME:-default}` syntax. The descriptions are written as
+// comments. To generate an `env_file` instead, use [WriteExample]. See
+// [Describe] for how dst is parsed.
+func WriteCompose(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("environment:\n")
+
+	for _, v := range vars {
+		if v.Desc != "" {
+			fmt.Fprintf(&sb, "  # %s\n", v.Desc)
+		}
+
+		var value string
+		switch {
+		case v.Required:
+			value = fmt.Sprintf("${%s:?%s is required}", v.Name, v.Name)
+		case v.Default != "":
+			// "$" must be escaped to prevent interpolation.
+			value = fmt.Sprintf("${%s:-%s}", v.Name, strings.ReplaceAll(v.Default, "$", "$$"))
+		default:
+			value = fmt.Sprintf("${%s:-}", v.Name)
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", v.Name, strconv.Quote(value))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteKubernetesEnv writes the `env:` section of a Kubernetes container spec
+// documenting the environment variables parsed from the fields of the provided
+// struct to w, so that the manifests can be derived from code. The values of
+// the variables marked as secret are referenced from the Secret named by name
+// using `valueFrom.secretKeyRef`, the others are referenced from the ConfigMap
+// with the same name using `valueFrom.configMapKeyRef` (see [WriteConfigMap]).
+// The references of the variables not marked as required are optional. See
+// [Describe] for how dst is parsed.
+func WriteKubernetesEnv(w io.Writer, dst any, name string, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("env:\n")
+
+	for _, v := range vars {
+		ref := "configMapKeyRef"
+		if v.Secret {
+			ref = "secretKeyRef"
+		}
+
+		fmt.Fprintf(&sb, "  - name: %s\n", v.Name)
+		sb.WriteString("    valueFrom:\n")
+		fmt.Fprintf(&sb, "      %s:\n", ref)
+		fmt.Fprintf(&sb, "        name: %s\n", strconv.Quote(name))
+		fmt.Fprintf(&sb, "        key: %s\n", v.Name)
+		if !v.Required {
+			sb.WriteString("        optional: true\n")
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteConfigMap writes a Kubernetes ConfigMap manifest named by name to w. It
+// contains the environment variables parsed from the fields of the provided
+// struct, set to their default values, or to their examples (parsed from the
+// `example` tag), if there are no default ones. The variables marked as secret
+// are skipped: they are expected to be stored in a Secret instead (see
+// [WriteKubernetesEnv]). See [Describe] for how dst is parsed.
+func WriteConfigMap(w io.Writer, dst any, name string, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("apiVersion: v1\n")
+	sb.WriteString("kind: ConfigMap\n")
+	sb.WriteString("metadata:\n")
+	fmt.Fprintf(&sb, "  name: %s\n", strconv.Quote(name))
+	sb.WriteString("data:\n")
+
+	for _, v := range vars {
+		if v.Secret {
+			continue
+		}
+		if v.Desc != "" {
+			fmt.Fprintf(&sb, "  # %s\n", v.Desc)
+		}
+		value := v.Default
+		if value == "" {
+			value = v.Example
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", v.Name, strconv.Quote(value))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteHelmValues writes a `values.yaml` skeleton for a Helm chart to w. The
+// environment variables parsed from the fields of the provided struct are
+// placed under the `env` key, set to their default values, or to their
+// examples (parsed from the `example` tag), if there are no default ones. The
+// variables marked as secret are not included: they are expected to be stored
+// in the Secret named by `envSecretName`. Use [WriteHelmEnv] to generate the
+// corresponding template. See [Describe] for how dst is parsed.
+func WriteHelmValues(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# the name of the Secret holding the secret environment variables.\n")
+	sb.WriteString("envSecretName: \"\"\n")
+	sb.WriteString("env:\n")
+
+	for _, v := range vars {
+		if v.Secret {
+			continue
+		}
+		if v.Desc != "" {
+			fmt.Fprintf(&sb, "  # %s\n", v.Desc)
+		}
+		value := v.Default
+		if value == "" {
+			value = v.Example
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", v.Name, strconv.Quote(value))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteHelmEnv writes the templated `env:` section of a container spec for a
+// Helm chart to w, matching the values generated by [WriteHelmValues]. The
+// values are read from `.Values.env`, and the required variables are checked
+// using the `required` function, so that rendering fails if they are empty.
+// The values of the variables marked as secret are referenced from the Secret
+// named by `.Values.envSecretName`. See [Describe] for how dst is parsed.
+func WriteHelmEnv(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("env:\n")
+
+	for _, v := range vars {
+		fmt.Fprintf(&sb, "  - name: %s\n", v.Name)
+
+		if v.Secret {
+			sb.WriteString("    valueFrom:\n")
+			sb.WriteString("      secretKeyRef:\n")
+			sb.WriteString("        name: {{ .Values.envSecretName | quote }}\n")
+			fmt.Fprintf(&sb, "        key: %s\n", v.Name)
+			if !v.Required {
+				sb.WriteString("        optional: true\n")
+			}
+			continue
+		}
+
+		value := fmt.Sprintf("index .Values.env %q", v.Name)
+		if v.Required {
+			value = fmt.Sprintf("required %q (%s)", v.Name+" is required", value)
+		}
+		fmt.Fprintf(&sb, "    value: {{ %s | quote }}\n", value)
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteTerraform writes Terraform `variable` blocks documenting the
+// environment variables parsed from the fields of the provided struct to w, for
+// the teams that pass the application config through infrastructure as code.
+// The names of the variables are lowercased, and each block contains the type,
+// the description, the default value (unless the variable is marked as
+// required), and the `sensitive` flag for the variables marked as secret. See
+// [Describe] for how dst is parsed.
+func WriteTerraform(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	l := newLoader(nil, opts...)
+
+	var sb strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		typ := terraformType(v.Type)
+		attrs := [][2]string{{"type", typ}}
+		if v.Desc != "" {
+			attrs = append(attrs, [2]string{"description", terraformValue(v.Desc)})
+		}
+
+		if !v.Required {
+			var def any
+			switch {
+			case v.Default != "":
+				if def, err = l.jsonSchemaValue(v.Type, v.Default); err != nil {
+					return fmt.Errorf("env: invalid default value %q of %s: %w", v.Default, v.Name, err)
+				}
+			case strings.HasPrefix(typ, "list"):
+				def = []any{}
+			default:
+				def = ""
+			}
+			attrs = append(attrs, [2]string{"default", terraformValue(def)})
+		}
+
+		if v.Secret {
+			attrs = append(attrs, [2]string{"sensitive", "true"})
+		}
+
+		// align the attributes the same way `terraform fmt` does.
+		width := 0
+		for _, attr := range attrs {
+			if len(attr[0]) > width {
+				width = len(attr[0])
+			}
+		}
+
+		fmt.Fprintf(&sb, "variable %q {\n", strings.ToLower(v.Name))
+		for _, attr := range attrs {
+			fmt.Fprintf(&sb, "  %-*s = %s\n", width, attr[0], attr[1])
+		}
+		sb.WriteString("}\n")
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// terraformType returns the Terraform type corresponding to t.
+func terraformType(t reflect.Type) string {
+	switch jsonSchemaType(t) {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "list(" + terraformType(t.Elem()) + ")"
+	default:
+		return "string"
+	}
+}
+
+// terraformValue formats the JSON value returned by jsonSchemaValue as a
+// Terraform literal.
+func terraformValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		// "${" and "%{" start template sequences and must be escaped.
+		s := strconv.Quote(value)
+		s = strings.ReplaceAll(s, "${", "$${")
+		return strings.ReplaceAll(s, "%{", "%%{")
+	case []any:
+		elems := make([]string, len(value))
+		for i, elem := range value {
+			elems[i] = terraformValue(elem)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// jsonSchema is a JSON Schema describing the environment.
+type jsonSchema struct {
+	Schema     string                         `json:"$schema"`
+	Type       string                         `json:"type"`
+	Properties map[string]*jsonSchemaProperty `json:"properties"`
+	Required   []string                       `json:"required,omitempty"`
+}
+
+// jsonSchemaProperty is a JSON Schema describing a single environment variable.
+type jsonSchemaProperty struct {
+	Type        string              `json:"type"`
+	Items       *jsonSchemaProperty `json:"items,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Default     any                 `json:"default,omitempty"`
+	Examples    []string            `json:"examples,omitempty"`
+	Enum        []any               `json:"enum,omitempty"`
+	Minimum     *float64            `json:"minimum,omitempty"`
+	Maximum     *float64            `json:"maximum,omitempty"`
+	WriteOnly   bool                `json:"writeOnly,omitempty"`
+	GoType      string              `json:"x-go-type,omitempty"`
+	GoField     string              `json:"x-go-field,omitempty"`
+	Deprecated  []string            `json:"x-deprecated-names,omitempty"`
+}
+
+// jsonSchemaProperty builds a JSON Schema property for v.
+func (l *loader) jsonSchemaProperty(v Var) (*jsonSchemaProperty, error) {
+	prop := &jsonSchemaProperty{
+		Type:        jsonSchemaType(v.Type),
+		Description: v.Desc,
+		WriteOnly:   v.Secret,
+		GoType:      v.Type.String(),
+		GoField:     v.Field,
+		Deprecated:  v.Deprecated,
+	}
+	if v.Example != "" {
+		prop.Examples = []string{v.Example}
+	}
+
+	// enum/min/max restrict the elements of a slice, not the slice itself.
+	restricted, elemType := prop, v.Type
+	if prop.Type == "array" {
+		elemType = v.Type.Elem()
+		prop.Items = &jsonSchemaProperty{Type: jsonSchemaType(elemType), GoType: elemType.String()}
+		restricted = prop.Items
+	}
+
+	if v.Default != "" {
+		def, err := l.jsonSchemaValue(v.Type, v.Default)
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid default value %q of %s: %w", v.Default, v.Name, err)
+		}
+		prop.Default = def
+	}
+
+	if s, ok := v.tag.Lookup("enum"); ok {
+		for _, value := range strings.Split(s, ",") {
+			e, err := l.jsonSchemaValue(elemType, value)
+			if err != nil {
+				return nil, fmt.Errorf("env: invalid enum value %q of %s: %w", value, v.Name, err)
+			}
+			restricted.Enum = append(restricted.Enum, e)
+		}
+	}
+
+	for _, bound := range []struct {
+		tag string
+		dst **float64
+	}{
+		{tag: "min", dst: &restricted.Minimum},
+		{tag: "max", dst: &restricted.Maximum},
+	} {
+		s, ok := v.tag.Lookup(bound.tag)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid %s %q of %s", bound.tag, s, v.Name)
+		}
+		*bound.dst = &f
+	}
+
+	return prop, nil
+}
+
+// jsonSchemaType returns the JSON Schema type corresponding to t. The types
+// that are parsed from their text representation, e.g. [time.Duration] or
+// [encoding.TextUnmarshaler] implementations, are described as strings.
+func jsonSchemaType(t reflect.Type) string {
+	v := reflect.New(t).Elem()
+	switch {
+	case typeOf(v, durationType), implements(v, unmarshalerIface):
+		return "string"
+	case kindOf(v, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64),
+		kindOf(v, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64):
+		return "integer"
+	case kindOf(v, reflect.Float32, reflect.Float64):
+		return "number"
+	case kindOf(v, reflect.Bool):
+		return "boolean"
+	case kindOf(v, reflect.Slice):
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+// jsonSchemaValue parses s as a value of type t and converts it to the
+// corresponding JSON value.
+func (l *loader) jsonSchemaValue(t reflect.Type, s string) (any, error) {
+	if t == readerType || t == readCloserType {
+		// the value of a file var is the name of the file.
+		return s, nil
+	}
+
+	v := reflect.New(t).Elem()
+	if err := l.setField(v, s); err != nil {
+		return nil, err
+	}
+
+	switch jsonSchemaType(t) {
+	case "integer", "number", "boolean":
+		return v.Interface(), nil
+	case "array":
+		values := make([]any, 0, v.Len())
+		for _, elem := range strings.Split(s, l.sliceSep) {
+			value, err := l.jsonSchemaValue(t.Elem(), elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		return s, nil
+	}
+}
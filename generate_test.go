@@ -0,0 +1,401 @@
+package env_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// generateConfig is a config used to test the generators.
+type generateConfig struct {
+	DB struct {
+		Host     string `env:"DB_HOST,required" desc:"database host" example:"db.example.com"`
+		Port     int    `env:"DB_PORT" default:"5432" desc:"database port"`
+		Password string `env:"DB_PASSWORD,required,secret" desc:"database password"`
+	}
+	Timeout time.Duration `env:"TIMEOUT" default:"5s" desc:"request timeout | per attempt"`
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	const markdown = "| Name | Type | Required | Default | Description | Example |\n" +
+		"|------|------|----------|---------|-------------|---------|\n" +
+		"| `DB_HOST` | `string` | yes |  | database host | `db.example.com` |\n" +
+		"| `DB_PORT` | `int` | no | `5432` | database port |  |\n" +
+		"| `DB_PASSWORD` | `string` | yes |  | database password |  |\n" +
+		"| `TIMEOUT` | `time.Duration` | no | `5s` | request timeout \\| per attempt |  |\n"
+
+	var buf bytes.Buffer
+	err := env.WriteMarkdown(&buf, new(generateConfig))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), markdown)
+
+	err = env.WriteMarkdown(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		err := env.WriteJSONSchema(new(bytes.Buffer), nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("generate config", func(t *testing.T) {
+		const schema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "DB_HOST": {
+      "type": "string",
+      "description": "database host",
+      "examples": [
+        "db.example.com"
+      ],
+      "x-go-type": "string",
+      "x-go-field": "DB.Host"
+    },
+    "DB_PASSWORD": {
+      "type": "string",
+      "description": "database password",
+      "writeOnly": true,
+      "x-go-type": "string",
+      "x-go-field": "DB.Password"
+    },
+    "DB_PORT": {
+      "type": "integer",
+      "description": "database port",
+      "default": 5432,
+      "x-go-type": "int",
+      "x-go-field": "DB.Port"
+    },
+    "TIMEOUT": {
+      "type": "string",
+      "description": "request timeout | per attempt",
+      "default": "5s",
+      "x-go-type": "time.Duration",
+      "x-go-field": "Timeout"
+    }
+  },
+  "required": [
+    "DB_HOST",
+    "DB_PASSWORD"
+  ]
+}
+`
+		var buf bytes.Buffer
+		err := env.WriteJSONSchema(&buf, new(generateConfig))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, buf.String(), schema)
+	})
+
+	t.Run("restrictions", func(t *testing.T) {
+		const schema = `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"LEVEL":{"type":"string","default":"info","enum":["debug","info"],"x-go-type":"string","x-go-field":"Level"},` +
+			`"PORTS":{"type":"array","items":{"type":"integer","minimum":1,"maximum":65535,"x-go-type":"int"},"default":[80,443],"x-go-type":"[]int","x-go-field":"Ports"},` +
+			`"RATIO":{"type":"number","default":0,"minimum":0,"maximum":0.5,"x-go-type":"float64","x-go-field":"Ratio"}}}`
+
+		var cfg struct {
+			Level string  `env:"LEVEL" default:"info" enum:"debug,info"`
+			Ports []int   `env:"PORTS" default:"80 443" min:"1" max:"65535"`
+			Ratio float64 `env:"RATIO" min:"0" max:"0.5"`
+		}
+
+		var buf bytes.Buffer
+		err := env.WriteJSONSchema(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.JSONEqual[E](t, buf.String(), schema)
+	})
+
+	t.Run("invalid restriction", func(t *testing.T) {
+		var cfg struct {
+			Port int `env:"PORT" enum:"80,http"`
+		}
+		err := env.WriteJSONSchema(new(bytes.Buffer), &cfg)
+		assert.ErrorContains[E](t, err, "invalid enum value \"http\" of PORT")
+	})
+}
+
+func TestWriteExample(t *testing.T) {
+	const example = "# database host (required)\n" +
+		"DB_HOST=db.example.com\n" +
+		"\n" +
+		"# database port\n" +
+		"DB_PORT=5432\n" +
+		"\n" +
+		"# database password (required, secret)\n" +
+		"DB_PASSWORD=\n" +
+		"\n" +
+		"# request timeout | per attempt\n" +
+		"TIMEOUT=5s\n"
+
+	var buf bytes.Buffer
+	err := env.WriteExample(&buf, new(generateConfig))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), example)
+
+	// the result must be readable by the dotenv parser.
+	m, err := env.ParseDotenv(&buf)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, m["DB_PORT"], "5432")
+
+	err = env.WriteExample(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteCompose(t *testing.T) {
+	const compose = "environment:\n" +
+		"  # database host\n" +
+		"  DB_HOST: \"${DB_HOST:?DB_HOST is required}\"\n" +
+		"  # database port\n" +
+		"  DB_PORT: \"${DB_PORT:-5432}\"\n" +
+		"  # database password\n" +
+		"  DB_PASSWORD: \"${DB_PASSWORD:?DB_PASSWORD is required}\"\n" +
+		"  # request timeout | per attempt\n" +
+		"  TIMEOUT: \"${TIMEOUT:-5s}\"\n"
+
+	var buf bytes.Buffer
+	err := env.WriteCompose(&buf, new(generateConfig))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), compose)
+
+	err = env.WriteCompose(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteKubernetesEnv(t *testing.T) {
+	const manifest = `env:
+  - name: DB_HOST
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: DB_HOST
+  - name: DB_PORT
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: DB_PORT
+        optional: true
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: "app"
+        key: DB_PASSWORD
+  - name: TIMEOUT
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: TIMEOUT
+        optional: true
+`
+	var buf bytes.Buffer
+	err := env.WriteKubernetesEnv(&buf, new(generateConfig), "app")
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), manifest)
+
+	err = env.WriteKubernetesEnv(&buf, nil, "app")
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteConfigMap(t *testing.T) {
+	const manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  # database host
+  DB_HOST: "db.example.com"
+  # database port
+  DB_PORT: "5432"
+  # request timeout | per attempt
+  TIMEOUT: "5s"
+`
+	var buf bytes.Buffer
+	err := env.WriteConfigMap(&buf, new(generateConfig), "app")
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), manifest)
+
+	err = env.WriteConfigMap(&buf, nil, "app")
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteHelmValues(t *testing.T) {
+	const values = `# the name of the Secret holding the secret environment variables.
+envSecretName: ""
+env:
+  # database host
+  DB_HOST: "db.example.com"
+  # database port
+  DB_PORT: "5432"
+  # request timeout | per attempt
+  TIMEOUT: "5s"
+`
+	var buf bytes.Buffer
+	err := env.WriteHelmValues(&buf, new(generateConfig))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), values)
+
+	err = env.WriteHelmValues(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteHelmEnv(t *testing.T) {
+	const template = `env:
+  - name: DB_HOST
+    value: {{ required "DB_HOST is required" (index .Values.env "DB_HOST") | quote }}
+  - name: DB_PORT
+    value: {{ index .Values.env "DB_PORT" | quote }}
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: {{ .Values.envSecretName | quote }}
+        key: DB_PASSWORD
+  - name: TIMEOUT
+    value: {{ index .Values.env "TIMEOUT" | quote }}
+`
+	var buf bytes.Buffer
+	err := env.WriteHelmEnv(&buf, new(generateConfig))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), template)
+
+	err = env.WriteHelmEnv(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestWriteTerraform(t *testing.T) {
+	const variables = `variable "db_host" {
+  type        = string
+  description = "database host"
+}
+
+variable "db_port" {
+  type        = number
+  description = "database port"
+  default     = 5432
+}
+
+variable "db_password" {
+  type        = string
+  description = "database password"
+  sensitive   = true
+}
+
+variable "timeout" {
+  type        = string
+  description = "request timeout | per attempt"
+  default     = "5s"
+}
+
+variable "hosts" {
+  type    = list(string)
+  default = []
+}
+
+variable "template" {
+  type    = string
+  default = "$${HOME}"
+}
+`
+	var cfg struct {
+		Config   generateConfig
+		Hosts    []string `env:"HOSTS"`
+		Template string   `env:"TEMPLATE" default:"${HOME}"`
+	}
+
+	var buf bytes.Buffer
+	err := env.WriteTerraform(&buf, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), variables)
+
+	err = env.WriteTerraform(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestGenerateDefaultTemplate(t *testing.T) {
+	var cfg struct {
+		Host string `env:"HOST" default:"localhost"`
+		Base int    `env:"BASE_PORT" default:"8000"`
+		Port int    `env:"PORT,template" default:"{{.Base}}"`
+		URL  string `env:"URL,template" default:"http://{{.Host}}:{{.Port}}"`
+	}
+
+	t.Run("example", func(t *testing.T) {
+		const example = "HOST=localhost\n" +
+			"\n" +
+			"BASE_PORT=8000\n" +
+			"\n" +
+			"PORT=8000\n" +
+			"\n" +
+			"URL=http://localhost:8000\n"
+
+		var buf bytes.Buffer
+		err := env.WriteExample(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, buf.String(), example)
+	})
+
+	t.Run("json schema", func(t *testing.T) {
+		const schema = `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"BASE_PORT":{"type":"integer","default":8000,"x-go-type":"int","x-go-field":"Base"},` +
+			`"HOST":{"type":"string","default":"localhost","x-go-type":"string","x-go-field":"Host"},` +
+			`"PORT":{"type":"integer","default":8000,"x-go-type":"int","x-go-field":"Port"},` +
+			`"URL":{"type":"string","default":"http://localhost:8000","x-go-type":"string","x-go-field":"URL"}}}`
+
+		var buf bytes.Buffer
+		err := env.WriteJSONSchema(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.JSONEqual[E](t, buf.String(), schema)
+	})
+
+	t.Run("terraform", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := env.WriteTerraform(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Contains[E](t, buf.String(), "variable \"port\" {\n  type    = number\n  default = 8000\n}\n")
+		assert.Contains[E](t, buf.String(), "default = \"http://localhost:8000\"")
+	})
+
+	t.Run("required reference", func(t *testing.T) {
+		var cfg struct {
+			Host string `env:"HOST,required"`
+			URL  string `env:"URL,template" default:"http://{{.Host}}"`
+		}
+
+		var buf bytes.Buffer
+		err := env.WriteExample(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, buf.String(), "# required\nHOST=\n\nURL=http://\n")
+	})
+}
+
+func TestGenerateOpaqueVars(t *testing.T) {
+	var cfg struct {
+		Cert  io.Reader          `env:"CERT,file" default:"/etc/hosts"`
+		Token env.Lazy[int]      `env:"TOKEN,lazy" default:"42"`
+		Hosts env.Lazy[[]string] `env:"HOSTS,lazy" default:"a b"`
+	}
+
+	t.Run("json schema", func(t *testing.T) {
+		const schema = `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"CERT":{"type":"string","default":"/etc/hosts","x-go-type":"io.Reader","x-go-field":"Cert"},` +
+			`"HOSTS":{"type":"array","items":{"type":"string","x-go-type":"string"},"default":["a","b"],"x-go-type":"[]string","x-go-field":"Hosts"},` +
+			`"TOKEN":{"type":"integer","default":42,"x-go-type":"int","x-go-field":"Token"}}}`
+
+		var buf bytes.Buffer
+		err := env.WriteJSONSchema(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.JSONEqual[E](t, buf.String(), schema)
+	})
+
+	t.Run("terraform", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := env.WriteTerraform(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Contains[E](t, buf.String(), "variable \"cert\" {\n  type    = string\n  default = \"/etc/hosts\"\n}\n")
+		assert.Contains[E](t, buf.String(), "variable \"token\" {\n  type    = number\n  default = 42\n}\n")
+		assert.Contains[E](t, buf.String(), "variable \"hosts\" {\n  type    = list(string)\n  default = [\"a\", \"b\"]\n}\n")
+	})
+}
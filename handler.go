@@ -0,0 +1,83 @@
+package env
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Handler returns an [http.Handler] that renders the current values of the
+// provided config with the values of the variables marked as secret redacted.
+// It is intended to be used as an internal debug endpoint, similar to
+// [expvar]. The config is rendered as HTML, if the request's Accept header
+// contains "text/html", and as JSON otherwise. It respects the same options as
+// [Marshal] does. If the [WithSources] option is provided, the sources of the
+// variables are rendered as well:
+//
+//	sources := make(map[string]string)
+//	if err := env.Load(&cfg, env.WithSources(sources)); err != nil {
+//		// handle error
+//	}
+//	http.Handle("/debug/config", env.Handler(&cfg, env.WithSources(sources)))
+//
+// Since src is read on every request, pass the same [WithLocker] option as to
+// [Refresh] or [Reload] if src is updated in the background.
+func Handler(src any, opts ...Option) http.Handler {
+	l := newLoader(nil, opts...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.locker != nil {
+			l.locker.Lock()
+		}
+		vars, err := marshalVars(src, opts...)
+		if l.locker != nil {
+			l.locker.Unlock()
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		entries := make([]handlerEntry, len(vars))
+		for i, v := range vars {
+			entries[i] = handlerEntry{
+				Name:   v.name,
+				Field:  v.field,
+				Value:  v.redacted(),
+				Source: v.source,
+			}
+		}
+
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = handlerTemplate.Execute(w, entries)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
+
+// handlerEntry is a single environment variable rendered by [Handler].
+type handlerEntry struct {
+	Name   string `json:"name"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+// handlerTemplate is the HTML template used by [Handler].
+var handlerTemplate = template.Must(template.New("config").Parse(`<!DOCTYPE html>
+<html>
+<head><title>config</title></head>
+<body>
+<table>
+<tr><th>Name</th><th>Field</th><th>Value</th><th>Source</th></tr>
+{{- range .}}
+<tr><td>{{.Name}}</td><td>{{.Field}}</td><td>{{.Value}}</td><td>{{.Source}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,93 @@
+package env_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestHandler(t *testing.T) {
+	m := env.Map{
+		"DB_HOST":     "localhost",
+		"DB_PASSWORD": "qwerty",
+	}
+
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Port     int    `env:"DB_PORT" default:"5432"`
+		Password string `env:"DB_PASSWORD,secret"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(m, &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+
+	h := env.Handler(&cfg, env.WithSources(sources))
+
+	t.Run("json", func(t *testing.T) {
+		const body = `[{"name":"DB_HOST","field":"Host","value":"localhost","source":"env"},` +
+			`{"name":"DB_PORT","field":"Port","value":"5432","source":"default"},` +
+			`{"name":"DB_PASSWORD","field":"Password","value":"***","source":"env"}]` + "\n"
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal[E](t, w.Code, http.StatusOK)
+		assert.Equal[E](t, w.Header().Get("Content-Type"), "application/json")
+		assert.JSONEqual[E](t, w.Body.String(), body)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal[E](t, w.Code, http.StatusOK)
+		assert.Contains[E](t, w.Body.String(), "<td>DB_PASSWORD</td><td>Password</td><td>***</td>")
+		assert.False[E](t, strings.Contains(w.Body.String(), "qwerty"))
+	})
+
+	t.Run("with locker", func(t *testing.T) {
+		var cfg struct {
+			Port int `env:"PORT"`
+		}
+		var mu countingLocker
+		h := env.Handler(&cfg, env.WithLocker(&mu))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sources := map[string]string{"PORT": "test"}
+			for i := 0; i < 10; i++ {
+				m := env.Map{"PORT": strconv.Itoa(8080 + i)}
+				assert.NoErr[E](t, env.ReloadFrom(m, &cfg, sources, env.WithLocker(&mu)))
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}
+		wg.Wait()
+		assert.Equal[E](t, mu.locks, 20)
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		env.Handler(nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal[E](t, w.Code, http.StatusInternalServerError)
+	})
+}
+
+// countingLocker is a [sync.Mutex] that counts the Lock calls.
+type countingLocker struct {
+	sync.Mutex
+	locks int
+}
+
+func (l *countingLocker) Lock() { l.Mutex.Lock(); l.locks++ }
@@ -0,0 +1,102 @@
+// Package manifest implements decoding of the JSON Schema generated by
+// env.WriteJSONSchema, which is used as a manifest by the commands.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a subset of the JSON Schema generated by env.WriteJSONSchema.
+type Schema struct {
+	Properties map[string]*Property `json:"properties"`
+	Required   []string             `json:"required"`
+}
+
+// Property is a subset of the JSON Schema describing a single variable.
+type Property struct {
+	Type       string    `json:"type"`
+	Items      *Property `json:"items"`
+	Default    any       `json:"default"`
+	Enum       []any     `json:"enum"`
+	Minimum    *float64  `json:"minimum"`
+	Maximum    *float64  `json:"maximum"`
+	WriteOnly  bool      `json:"writeOnly"`
+	GoType     string    `json:"x-go-type"`
+	GoField    string    `json:"x-go-field"`
+	Deprecated []string  `json:"x-deprecated-names"`
+}
+
+// Read reads and decodes the JSON Schema named by name.
+func Read(name string) (*Schema, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	if s.Properties == nil {
+		return nil, fmt.Errorf("decoding %s: no properties", name)
+	}
+
+	return &s, nil
+}
+
+// Parse parses value as the type described by p, the same way env.Load parses
+// it into the struct field. sep is used to split slice values, the elements
+// are returned as []any. The integers and floats are checked against the bit
+// size of their Go type, e.g. 300 is out of range for int8. The values of other
+// types are returned as is.
+func (p *Property) Parse(value, sep string) (any, error) {
+	if p.Type == "array" {
+		if p.Items == nil {
+			return value, nil
+		}
+		var elems []any
+		for _, s := range strings.Split(value, sep) {
+			elem, err := p.Items.Parse(s, sep)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return elems, nil
+	}
+
+	switch {
+	case p.GoType == "time.Duration":
+		return time.ParseDuration(value)
+	case p.Type == "integer" && strings.HasPrefix(p.GoType, "uint"):
+		return strconv.ParseUint(value, 10, bitSize(p.GoType))
+	case p.Type == "integer":
+		return strconv.ParseInt(value, 10, bitSize(p.GoType))
+	case p.Type == "number":
+		return strconv.ParseFloat(value, bitSize(p.GoType))
+	case p.Type == "boolean":
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// bitSize returns the bit size of the numeric Go type named by goType, or 64,
+// if the size is unknown (e.g. for int or a named type).
+func bitSize(goType string) int {
+	switch goType {
+	case "int8", "uint8":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	default:
+		return 64
+	}
+}
@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(data), 0o600)
+		assert.NoErr[F](t, err)
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		path := write("valid.json", `{"properties":{"PORT":{"type":"integer","default":8080,"x-go-field":"Port"}},"required":["PORT"]}`)
+		s, err := Read(path)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, s.Required, []string{"PORT"})
+		assert.Equal[E](t, s.Properties["PORT"].Type, "integer")
+		assert.Equal[E](t, s.Properties["PORT"].Default, any(8080.0))
+		assert.Equal[E](t, s.Properties["PORT"].GoField, "Port")
+	})
+
+	t.Run("no properties", func(t *testing.T) {
+		_, err := Read(write("empty.json", `{}`))
+		assert.ErrorContains[E](t, err, "no properties")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := Read(filepath.Join(dir, "missing.json"))
+		assert.IsErr[E](t, err, os.ErrNotExist)
+	})
+}
+
+func TestPropertyParse(t *testing.T) {
+	test := func(p *Property, value string, want any) {
+		t.Helper()
+		got, err := p.Parse(value, ",")
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, got, want)
+	}
+
+	test(&Property{Type: "integer", GoType: "int"}, "05432", any(int64(5432)))
+	test(&Property{Type: "integer", GoType: "uint16"}, "80", any(uint64(80)))
+	test(&Property{Type: "number"}, "0.50", any(0.5))
+	test(&Property{Type: "boolean"}, "1", any(true))
+	test(&Property{Type: "string", GoType: "time.Duration"}, "60s", any(time.Minute))
+	test(&Property{Type: "string"}, "foo", any("foo"))
+	test(&Property{Type: "array", Items: &Property{Type: "integer"}}, "1,02", any([]any{int64(1), int64(2)}))
+
+	_, err := (&Property{Type: "integer"}).Parse("foo", ",")
+	assert.ErrorContains[E](t, err, "invalid syntax")
+
+	_, err = (&Property{Type: "integer", GoType: "int8"}).Parse("300", ",")
+	assert.ErrorContains[E](t, err, "value out of range")
+
+	_, err = (&Property{Type: "array", Items: &Property{Type: "integer", GoType: "uint16"}}).Parse("80,70000", ",")
+	assert.ErrorContains[E](t, err, "value out of range")
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// Koanf is a tiny subset of the [github.com/knadh/koanf] API. *koanf.Koanf
+// implements it, which allows projects standardizing on koanf to use this
+// package without making it depend on koanf.
+type Koanf interface {
+	Exists(path string) bool
+	String(path string) string
+}
+
+// KoanfProvider returns a [Provider] that looks up environment variables in the
+// provided [Koanf] instance. The path of a variable is derived from its name by
+// converting it to lowercase, e.g. DB_HOST becomes db_host.
+func KoanfProvider(k Koanf) Provider {
+	return ProviderFunc(func(key string) (string, bool) {
+		key = strings.ToLower(key)
+		if !k.Exists(key) {
+			return "", false
+		}
+		return k.String(key), true
+	})
+}
+
+// KoanfSource is a koanf provider that loads environment variables into a
+// struct using this package and exposes the parsed values to koanf:
+//
+//	if err := k.Load(env.NewKoanfSource(env.OS, &cfg), nil); err != nil {
+//		// handle error
+//	}
+//
+// The keys are derived the same way [KoanfProvider] does it.
+type KoanfSource struct {
+	p    Provider
+	dst  any
+	opts []Option
+}
+
+// NewKoanfSource returns a new [KoanfSource] that loads environment variables
+// into dst using the specified [Provider] as their source. See [LoadFrom]
+// documentation for details.
+func NewKoanfSource(p Provider, dst any, opts ...Option) *KoanfSource {
+	return &KoanfSource{p: p, dst: dst, opts: opts}
+}
+
+// ReadBytes implements the koanf.Provider interface. It is not supported.
+func (*KoanfSource) ReadBytes() ([]byte, error) {
+	return nil, errors.New("env: koanf source does not support ReadBytes")
+}
+
+// Read implements the koanf.Provider interface. It loads environment variables
+// into the struct and returns the parsed values keyed by the lowercase names of
+// the variables.
+func (s *KoanfSource) Read() (map[string]any, error) {
+	if err := LoadFrom(s.p, s.dst, s.opts...); err != nil {
+		return nil, err
+	}
+
+	vars, err := Describe(s.dst, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(vars))
+	for _, v := range vars {
+		if v.opaque() {
+			continue
+		}
+		m[strings.ToLower(v.Name)] = v.field.Interface()
+	}
+
+	return m, nil
+}
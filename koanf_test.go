@@ -0,0 +1,52 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// fakeKoanf is a [env.Koanf] implementation backed by a map.
+type fakeKoanf map[string]string
+
+func (k fakeKoanf) Exists(path string) bool {
+	_, ok := k[path]
+	return ok
+}
+
+func (k fakeKoanf) String(path string) string { return k[path] }
+
+func TestKoanfProvider(t *testing.T) {
+	var cfg struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT" default:"5432"`
+	}
+	err := env.LoadFrom(env.KoanfProvider(fakeKoanf{"db_host": "localhost"}), &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Host, "localhost")
+	assert.Equal[E](t, cfg.Port, 5432)
+}
+
+func TestKoanfSource(t *testing.T) {
+	var cfg struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT,required"`
+	}
+
+	t.Run("read", func(t *testing.T) {
+		src := env.NewKoanfSource(env.Map{"DB_HOST": "localhost", "DB_PORT": "5432"}, &cfg)
+		m, err := src.Read()
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, m, map[string]any{"db_host": "localhost", "db_port": 5432})
+
+		_, err = src.ReadBytes()
+		assert.ErrorContains[E](t, err, "does not support ReadBytes")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := env.NewKoanfSource(env.Map{}, &cfg).Read()
+		assert.AsErr[E](t, err, new(*env.NotSetError))
+	})
+}
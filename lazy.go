@@ -0,0 +1,103 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Lazy is a field type whose value is looked up and parsed on the first call of
+// the [Lazy.Get] method instead of during [Load]/[LoadFrom]. It is useful for
+// expensive values (e.g. secrets fetched from a remote [Provider]) that are
+// only needed by rarely used code paths. The field must have the `lazy` option:
+//
+//	var cfg struct {
+//		Token env.Lazy[string] `env:"TOKEN,required,lazy"`
+//	}
+//	if err := env.Load(&cfg); err != nil {
+//		// handle error
+//	}
+//	token, err := cfg.Token.Get() // TOKEN is looked up here.
+//
+// T can be any type supported by [Load]. The other options (e.g. required or
+// expand) and the `default` tag are applied on the first call as well, so the
+// related errors are returned by [Lazy.Get]. The helpers that read the current
+// values of the fields (e.g. [Diff] or [Dump]) ignore the Lazy fields, so that
+// they never trigger the lookup. A Lazy value must not be copied after the
+// first use.
+type Lazy[T any] struct {
+	once  sync.Once
+	load  func(dst reflect.Value) error
+	value T
+	err   error
+}
+
+// Get returns the value, looking it up and parsing it on the first call. The
+// result of the first call, including an error, is returned by all subsequent
+// calls. It is safe for concurrent use.
+func (l *Lazy[T]) Get() (T, error) {
+	l.once.Do(func() {
+		if l.load == nil {
+			l.err = errLazyNotLoaded
+			return
+		}
+		l.err = l.load(reflect.ValueOf(&l.value).Elem())
+	})
+	return l.value, l.err
+}
+
+// initLazy implements the lazyValue interface.
+func (l *Lazy[T]) initLazy(load func(dst reflect.Value) error) {
+	*l = Lazy[T]{load: load}
+}
+
+// lazyType implements the lazyValue interface.
+func (*Lazy[T]) lazyType() reflect.Type {
+	return reflect.TypeOf(new(T)).Elem()
+}
+
+// lazyValue is the interface implemented by [Lazy].
+type lazyValue interface {
+	initLazy(load func(dst reflect.Value) error)
+	lazyType() reflect.Type
+}
+
+var (
+	lazyIface        = reflect.TypeOf(new(lazyValue)).Elem()
+	errLazyNotLoaded = errors.New("env: Lazy value is used before Load")
+)
+
+// initLazy configures the [Lazy] field of v to look up and parse its value on
+// the first use. The lookup bypasses the wrappers of the current loader (e.g.
+// the ones configured by [WithTrace] or [WithConcurrency]), which only make
+// sense during [Load], so only the provider and the options affecting the
+// parsing are kept.
+func (l *loader) initLazy(v Var, p Provider) {
+	lazy := &loader{
+		provider:       p,
+		sliceSep:       l.sliceSep,
+		expansionStyle: l.expansionStyle,
+		errorFormatter: l.errorFormatter,
+	}
+	v.field.Addr().Interface().(lazyValue).initLazy(func(dst reflect.Value) error {
+		return lazy.formatError(lazy.loadLazy(v, dst))
+	})
+}
+
+// loadLazy looks up the value of the [Lazy] field and parses it into dst.
+func (l *loader) loadLazy(v Var, dst reflect.Value) error {
+	value, _, ok, err := l.lookupVar(v)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if v.Required {
+			return &NotSetError{Names: []string{v.Name}}
+		}
+		value = v.Default
+	}
+	if err := l.setField(dst, value); err != nil {
+		return &ParseError{Name: v.Name, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package env_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestLazy(t *testing.T) {
+	t.Run("looked up on first use", func(t *testing.T) {
+		var lookups []string
+		p := env.ProviderFunc(func(key string) (string, bool) {
+			lookups = append(lookups, key)
+			return env.Map{"HOST": "localhost", "TOKEN": "qwerty", "PORT": "8080"}.LookupEnv(key)
+		})
+
+		var cfg struct {
+			Host  string           `env:"HOST"`
+			Token env.Lazy[string] `env:"TOKEN,required,lazy"`
+			Port  env.Lazy[int]    `env:"PORT,lazy"`
+		}
+		err := env.LoadFrom(p, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, lookups, []string{"HOST"})
+
+		token, err := cfg.Token.Get()
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, token, "qwerty")
+		assert.Equal[E](t, lookups, []string{"HOST", "TOKEN"})
+
+		// the value is cached.
+		_, _ = cfg.Token.Get()
+		assert.Len[E](t, lookups, 2)
+
+		port, err := cfg.Port.Get()
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, port, 8080)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		var cfg struct {
+			Retries env.Lazy[[]int] `env:"RETRIES,lazy" default:"1 2 3"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.NoErr[F](t, err)
+
+		retries, err := cfg.Retries.Get()
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, retries, []int{1, 2, 3})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var cfg struct {
+			Token env.Lazy[string] `env:"TOKEN,required,lazy"`
+			Port  env.Lazy[int]    `env:"PORT,lazy"`
+		}
+		err := env.LoadFrom(env.Map{"PORT": "-"}, &cfg)
+		assert.NoErr[F](t, err)
+
+		_, err = cfg.Token.Get()
+		assert.AsErr[E](t, err, new(*env.NotSetError))
+
+		_, err = cfg.Port.Get()
+		var parseErr *env.ParseError
+		assert.AsErr[F](t, err, &parseErr)
+		assert.Equal[E](t, parseErr.Name, "PORT")
+	})
+
+	t.Run("used before load", func(t *testing.T) {
+		var token env.Lazy[string]
+		_, err := token.Get()
+		assert.ErrorContains[E](t, err, "used before Load")
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		var cfg struct {
+			Token env.Lazy[string] `env:"TOKEN,lazy"`
+		}
+		err := env.LoadFrom(env.Map{"TOKEN": "qwerty"}, &cfg)
+		assert.NoErr[F](t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := cfg.Token.Get()
+				assert.NoErr[E](t, err)
+				assert.Equal[E](t, token, "qwerty")
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("option without type", func(t *testing.T) {
+		var cfg struct {
+			Token string `env:"TOKEN,lazy"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.IsErr[E](t, err, env.ErrInvalidTagOption)
+	})
+
+	t.Run("type without option", func(t *testing.T) {
+		var cfg struct {
+			Token env.Lazy[string] `env:"TOKEN"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.IsErr[E](t, err, env.ErrInvalidTagOption)
+	})
+
+	t.Run("helpers", func(t *testing.T) {
+		var cfg struct {
+			Host  string           `env:"HOST" default:"localhost"`
+			Token env.Lazy[string] `env:"TOKEN,lazy"`
+		}
+		var unused []string
+		err := env.LoadFrom(env.Map{"TOKEN": "qwerty"}, &cfg, env.WithUnusedReport("", &unused))
+		assert.NoErr[F](t, err)
+		assert.Len[E](t, unused, 0)
+
+		vars, err := env.Describe(&cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, vars[1].Type.String(), "string")
+
+		m, err := env.Marshal(&cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, m, env.Map{"HOST": "localhost"})
+	})
+}
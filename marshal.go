@@ -0,0 +1,234 @@
+package env
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marshal is the reverse of [Load]: it serializes the values of the provided
+// struct fields into a [Map], using the same `env` tags to build the names of
+// the environment variables. The values are formatted so that they can be
+// loaded back, which makes Marshal useful for generating configs and
+// round-trip tests. The following options are respected:
+//
+//   - [WithPrefix]: the prefix is added to each name
+//   - [WithSliceSeparator]: the separator is used to join slice values
+//   - [WithFilter]/[WithGroups]: only the matching variables are marshaled
+//
+// src must be a non-nil struct pointer, otherwise Marshal returns
+// [ErrInvalidArgument]. Note that secret values are NOT redacted.
+func Marshal(src any, opts ...Option) (Map, error) {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(Map, len(vars))
+	for _, v := range vars {
+		m[v.name] = v.value
+	}
+
+	return m, nil
+}
+
+// MarshalWriter is like [Marshal], but writes the result to w in the dotenv
+// format (see [ParseDotenv]), one variable per line, in the order of
+// declaration. Values containing special characters are double-quoted.
+func MarshalWriter(w io.Writer, src any, opts ...Option) error {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", v.name, quoteDotenv(v.value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export writes the values of the provided struct fields into the process
+// environment using [os.Setenv], so that child processes (e.g. spawned with
+// [os/exec]) inherit the same effective configuration. It respects the same
+// options as [Marshal] does. To export only the variables that were not set
+// and got their default values, combine the [WithSources] and [WithFilter]
+// options:
+//
+//	sources := make(map[string]string)
+//	if err := env.Load(&cfg, env.WithSources(sources)); err != nil {
+//		// handle error
+//	}
+//	onlyDefaults := env.WithFilter(func(v env.Var) bool {
+//		return sources[v.Name] == "default"
+//	})
+//	if err := env.Export(&cfg, onlyDefaults); err != nil {
+//		// handle error
+//	}
+func Export(src any, opts ...Option) error {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		if err := os.Setenv(v.name, v.value); err != nil {
+			return fmt.Errorf("env: setting %s: %w", v.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Dump is like [MarshalWriter], but returns the result as a string with the
+// values of the variables marked as secret redacted (unless the
+// [WithRevealedSecrets] option is provided). It is intended to be used to log
+// the config, e.g. at startup.
+func Dump(src any, opts ...Option) (string, error) {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&sb, "%s=%s\n", v.name, quoteDotenv(v.redacted()))
+	}
+
+	return sb.String(), nil
+}
+
+// Fingerprint returns a stable hash (hex-encoded SHA-256) of the values of the
+// provided struct fields, which can be used to detect configuration changes,
+// e.g. to decide whether a rolling restart is needed. The hash does not depend
+// on the order of the fields. The values of the variables marked as secret are
+// redacted before hashing (unless the [WithRevealedSecrets] option is
+// provided), so changing them does NOT change the fingerprint. It respects the
+// same options as [Marshal] does.
+func Fingerprint(src any, opts ...Option) (string, error) {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	sort.Slice(vars, func(i, j int) bool { return vars[i].name < vars[j].name })
+
+	h := sha256.New()
+	for _, v := range vars {
+		// use quoting to make the encoding unambiguous.
+		fmt.Fprintf(h, "%s=%q\n", v.name, v.redacted())
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// marshaledVar is an environment variable formatted by marshalVars.
+type marshaledVar struct {
+	name   string
+	field  string
+	value  string
+	source string // only if the WithSources option is provided.
+	secret bool
+}
+
+// redacted returns the value of the variable, or a placeholder, if the
+// variable is marked as secret.
+func (v marshaledVar) redacted() string {
+	if v.secret {
+		return redacted
+	}
+	return v.value
+}
+
+// marshalVars formats the values of the src struct fields, preserving the
+// order of declaration.
+func marshalVars(src any, opts ...Option) ([]marshaledVar, error) {
+	rv := reflect.ValueOf(src)
+	if !structPtr(rv) {
+		return nil, ErrInvalidArgument
+	}
+
+	l := newLoader(nil, opts...)
+
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+	vars = filterVars(vars, func(v Var) bool { return !v.opaque() })
+
+	marshaled := make([]marshaledVar, len(vars))
+	for i, v := range vars {
+		value, err := formatValue(v.field, l.sliceSep)
+		if err != nil {
+			return nil, err
+		}
+		marshaled[i] = marshaledVar{
+			name:   v.Name,
+			field:  v.Field,
+			value:  value,
+			source: l.sources[v.Name],
+			secret: v.Secret && !l.revealSecrets,
+		}
+	}
+
+	return marshaled, nil
+}
+
+// WriteShell writes a POSIX shell script to w that exports the values of the
+// provided struct fields, one `export NAME=value` line per variable, in the
+// order of declaration. The script can be sourced to bootstrap a local shell or
+// a CI job with the same effective configuration. The values are single-quoted
+// when necessary. The values of the variables marked as secret are redacted,
+// unless the [WithRevealedSecrets] option is provided. It respects the same
+// options as [Marshal] does.
+func WriteShell(w io.Writer, src any, opts ...Option) error {
+	vars, err := marshalVars(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&sb, "export %s=%s\n", v.name, quoteShell(v.redacted()))
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// quoteShell single-quotes s, if it contains characters that have a special
+// meaning in a POSIX shell.
+func quoteShell(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("@%+=:,./-_", r)
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return !safe(r) }) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteDotenv double-quotes s, if it contains whitespace or characters that
+// would be misinterpreted by [ParseDotenv].
+func quoteDotenv(s string) string {
+	if strings.ContainsAny(s, " \"'#\\\n\r\t") {
+		return strconv.Quote(s)
+	}
+	return s
+}
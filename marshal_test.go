@@ -0,0 +1,206 @@
+package env_test
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+type marshalConfig struct {
+	DB struct {
+		Host string `env:"DB_HOST"`
+		Port uint16 `env:"DB_PORT"`
+	}
+	Debug     bool            `env:"DEBUG"`
+	Ratio     float64         `env:"RATIO"`
+	Offset    int             `env:"OFFSET"`
+	Timeouts  []time.Duration `env:"TIMEOUTS"`
+	IP        net.IP          `env:"IP"`
+	Greeting  string          `env:"GREETING"`
+	Untracked string
+}
+
+func newMarshalConfig() *marshalConfig {
+	cfg := marshalConfig{
+		Debug:    true,
+		Ratio:    0.5,
+		Offset:   -1,
+		Timeouts: []time.Duration{time.Second, time.Minute},
+		IP:       net.IPv4(127, 0, 0, 1),
+		Greeting: "hello world",
+	}
+	cfg.DB.Host = "localhost"
+	cfg.DB.Port = 5432
+	return &cfg
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.Marshal(nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		var cfg struct {
+			Port complex64 `env:"PORT"`
+		}
+		_, err := env.Marshal(&cfg)
+		assert.IsErr[E](t, err, env.ErrUnsupportedType)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		src := newMarshalConfig()
+		m, err := env.Marshal(src, env.WithPrefix("APP_"), env.WithSliceSeparator(","))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, m, env.Map{
+			"APP_DB_HOST":  "localhost",
+			"APP_DB_PORT":  "5432",
+			"APP_DEBUG":    "true",
+			"APP_RATIO":    "0.5",
+			"APP_OFFSET":   "-1",
+			"APP_TIMEOUTS": "1s,1m0s",
+			"APP_IP":       "127.0.0.1",
+			"APP_GREETING": "hello world",
+		})
+
+		var dst marshalConfig
+		err = env.LoadFrom(m, &dst, env.WithPrefix("APP_"), env.WithSliceSeparator(","))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, dst.Timeouts, src.Timeouts)
+		assert.True[E](t, dst.IP.Equal(src.IP))
+	})
+}
+
+func TestMarshalWriter(t *testing.T) {
+	const dotenv = `DB_HOST=localhost
+DB_PORT=5432
+DEBUG=true
+RATIO=0.5
+OFFSET=-1
+TIMEOUTS="1s 1m0s"
+IP=127.0.0.1
+GREETING="hello world"
+`
+	var buf bytes.Buffer
+	err := env.MarshalWriter(&buf, newMarshalConfig())
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), dotenv)
+
+	m, err := env.ParseDotenv(&buf)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, m["TIMEOUTS"], "1s 1m0s")
+}
+
+func TestExport(t *testing.T) {
+	// register the variables to be restored after the test is finished.
+	t.Setenv("EXPORT_HOST", "")
+	t.Setenv("EXPORT_PORT", "")
+
+	cfg := struct {
+		Host string `env:"EXPORT_HOST"`
+		Port int    `env:"EXPORT_PORT"`
+	}{
+		Host: "localhost",
+		Port: 8080,
+	}
+	err := env.Export(&cfg, env.WithFilter(func(v env.Var) bool {
+		return v.Name == "EXPORT_PORT"
+	}))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, os.Getenv("EXPORT_HOST"), "")
+	assert.Equal[E](t, os.Getenv("EXPORT_PORT"), "8080")
+}
+
+func TestDump(t *testing.T) {
+	const dump = `DB_USER=admin
+DB_PASSWORD=***
+`
+	cfg := struct {
+		User     string `env:"DB_USER"`
+		Password string `env:"DB_PASSWORD,secret"`
+	}{
+		User:     "admin",
+		Password: "qwerty",
+	}
+	got, err := env.Dump(&cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, got, dump)
+}
+
+func TestWriteShell(t *testing.T) {
+	cfg := struct {
+		User     string   `env:"DB_USER"`
+		Password string   `env:"DB_PASSWORD,secret"`
+		Hosts    []string `env:"DB_HOSTS"`
+		Comment  string   `env:"COMMENT"`
+		Empty    string   `env:"EMPTY"`
+	}{
+		User:     "admin",
+		Password: "qwerty",
+		Hosts:    []string{"a.local", "b.local"},
+		Comment:  "it's $HOME",
+	}
+
+	t.Run("secrets redacted", func(t *testing.T) {
+		const script = `export DB_USER=admin
+export DB_PASSWORD='***'
+export DB_HOSTS='a.local b.local'
+export COMMENT='it'\''s $HOME'
+export EMPTY=''
+`
+		var buf bytes.Buffer
+		err := env.WriteShell(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, buf.String(), script)
+	})
+
+	t.Run("secrets revealed", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := env.WriteShell(&buf, &cfg, env.WithRevealedSecrets(), env.WithFilter(func(v env.Var) bool {
+			return v.Secret
+		}))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, buf.String(), "export DB_PASSWORD=qwerty\n")
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	type config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Password string `env:"PASSWORD,secret"`
+	}
+
+	fingerprint := func(cfg config) string {
+		t.Helper()
+		fp, err := env.Fingerprint(&cfg)
+		assert.NoErr[F](t, err)
+		return fp
+	}
+
+	base := fingerprint(config{Host: "localhost", Port: 8080, Password: "foo"})
+	assert.Len[E](t, base, 64)
+	assert.Equal[E](t, fingerprint(config{Host: "localhost", Port: 8080, Password: "foo"}), base)
+	assert.Equal[E](t, fingerprint(config{Host: "localhost", Port: 8080, Password: "bar"}), base)
+	assert.NotEqual[E](t, fingerprint(config{Host: "localhost", Port: 8081, Password: "foo"}), base)
+
+	// the order of the fields does not matter.
+	reordered := struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}{Port: 8080, Host: "localhost"}
+	fp1, err := env.Fingerprint(&reordered)
+	assert.NoErr[F](t, err)
+	fp2, err := env.Fingerprint(&struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}{Host: "localhost", Port: 8080})
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, fp1, fp2)
+}
@@ -0,0 +1,61 @@
+package env
+
+import "reflect"
+
+// Merge overlays the non-zero values of the src struct fields onto the
+// corresponding dst fields, enabling base-plus-override configuration flows.
+// Both dst and src must be non-nil struct pointers of the same type, otherwise
+// Merge returns [ErrInvalidArgument]. Only the fields with the `env` tag are
+// merged. To merge only the fields that were explicitly set in the environment
+// (including the zero ones), combine the [WithSources] and [WithFilter]
+// options:
+//
+//	sources := make(map[string]string)
+//	if err := env.Load(&override, env.WithSources(sources)); err != nil {
+//		// handle error
+//	}
+//	onlySet := env.WithFilter(func(v env.Var) bool {
+//		return sources[v.Name] != "" && sources[v.Name] != "default"
+//	})
+//	if err := env.Merge(&base, &override, onlySet); err != nil {
+//		// handle error
+//	}
+//
+// Note that if a filter is provided, the zero values are merged as well.
+func Merge(dst, src any, opts ...Option) error {
+	rd, rs := reflect.ValueOf(dst), reflect.ValueOf(src)
+	if !structPtr(rd) || !structPtr(rs) || rd.Type() != rs.Type() {
+		return ErrInvalidArgument
+	}
+
+	l := newLoader(nil, opts...)
+
+	dstVars, err := l.parseVars(rd.Elem(), "")
+	if err != nil {
+		return err
+	}
+
+	srcVars, err := l.parseVars(rs.Elem(), "")
+	if err != nil {
+		return err
+	}
+
+	// both structs have the same type, so the vars are guaranteed to be in the
+	// same order.
+	for i := range srcVars {
+		sv, dv := srcVars[i], dstVars[i]
+		if sv.opaque() {
+			continue
+		}
+		if l.filter != nil {
+			if !l.filter(sv) {
+				continue
+			}
+		} else if sv.field.IsZero() {
+			continue
+		}
+		dv.field.Set(sv.field)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestMerge(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+
+	t.Run("invalid argument", func(t *testing.T) {
+		err := env.Merge(new(config), new(struct{}))
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("non-zero fields", func(t *testing.T) {
+		base := config{Host: "localhost", Port: 8080, Debug: true}
+		override := config{Port: 8081}
+
+		err := env.Merge(&base, &override)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, base, config{Host: "localhost", Port: 8081, Debug: true})
+	})
+
+	t.Run("explicitly set fields", func(t *testing.T) {
+		base := config{Host: "localhost", Port: 8080, Debug: true}
+
+		var override config
+		sources := make(map[string]string)
+		err := env.LoadFrom(env.Map{"DEBUG": "false"}, &override, env.WithSources(sources))
+		assert.NoErr[F](t, err)
+
+		err = env.Merge(&base, &override, env.WithFilter(func(v env.Var) bool {
+			return sources[v.Name] != "default"
+		}))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, base, config{Host: "localhost", Port: 8080, Debug: false})
+	})
+}
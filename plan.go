@@ -0,0 +1,129 @@
+package env
+
+import "reflect"
+
+// PlanEntry describes what a single struct field would be set to by
+// [Load]/[LoadFrom]. It is reported by the [Plan] function.
+type PlanEntry struct {
+	Var            // Var is the environment variable parsed from the struct field.
+	Value   string // Value is the raw value of the variable. If the variable is marked as secret, it will be redacted.
+	Source  string // Source is the source of the value, see [WithSources] for details.
+	Default bool   // Default is true, if the variable is not set and the default value is used.
+}
+
+// Plan performs a dry run of [LoadFrom]: it looks up and parses environment
+// variables the same way, but leaves the provided struct untouched. It returns
+// a plan describing what each field would be set to and where the value comes
+// from. The returned error is the same [LoadFrom] would return, in which case
+// the plan contains the entries resolved before the error occurred (excluding
+// missing required variables). If dst implements the [Unmarshaler] interface,
+// its UnmarshalEnv method is called on a shallow copy of dst and the plan is
+// empty. Plan is useful to implement flags like `--check-config`.
+func Plan(p Provider, dst any, opts ...Option) ([]PlanEntry, error) {
+	var plan []PlanEntry
+
+	l := newLoader(p, opts...)
+	l.dryRun = true
+	l.resolveHooks = append(l.resolveHooks, func(e PlanEntry) {
+		plan = append(plan, e)
+	})
+
+	err := l.loadVars(dst)
+	return plan, err
+}
+
+// Explanation describes how a single struct field is resolved by
+// [Load]/[LoadFrom]. It is reported by the [Explain] function.
+type Explanation struct {
+	Var              // Var is the environment variable parsed from the struct field.
+	Tried   []string // Tried is the list of names looked up in order, including the deprecated ones.
+	Value   string   // Value is the raw value of the variable. If the variable is marked as secret, it will be redacted.
+	Source  string   // Source is the source of the value, see [WithSources] for details.
+	Default bool     // Default is true, if the variable is not set and the default value is used.
+	Err     error    // Err is the error that occurred while resolving the variable, if any.
+}
+
+// Explain is like [Plan], but it does not stop at the first error: it returns
+// a resolution trace for every field instead, which is useful to debug
+// configuration issues. If the variable is required but not set, Err is of
+// type [NotSetError]; if the value cannot be parsed, Err is the parsing error;
+// if the file named by the value of a variable marked with the file option
+// does not exist, Err is the error of [os.Stat].
+// If p is nil, the default [Provider] is used. The returned error is non-nil
+// only if the struct itself is invalid, e.g. it contains an invalid tag.
+func Explain(p Provider, dst any, opts ...Option) ([]Explanation, error) {
+	rv := reflect.ValueOf(dst)
+	if !structPtr(rv) {
+		return nil, ErrInvalidArgument
+	}
+
+	l := newLoader(p, opts...)
+
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the default templates are executed with the values the referenced
+	// fields would be set to.
+	var rendered map[string]string
+	var renderErr error
+	if hasTemplates(vars) {
+		rendered, renderErr = renderDefaults(l.provider, dst, l)
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+
+	explanations := make([]Explanation, len(vars))
+	for i, v := range vars {
+		e := Explanation{Var: v}
+
+		var ok bool
+		var err error
+		for _, name := range append([]string{v.Name}, v.Deprecated...) {
+			e.Tried = append(e.Tried, name)
+			if e.Value, e.Source, ok, err = l.lookupEnv(name, v.Expand); ok || err != nil {
+				break
+			}
+		}
+
+		switch {
+		case err != nil:
+			e.Err = err
+		case !ok && v.Required:
+			e.Err = &NotSetError{Names: []string{v.Name}}
+		case !ok && v.tmpl != nil:
+			// the templates are executed up to the first error.
+			value, done := rendered[v.Name]
+			if !done {
+				e.Source, e.Default, e.Err = sourceDefault, true, renderErr
+				break
+			}
+			e.Value, e.Source, e.Default = value, sourceDefault, true
+			e.Err = l.checkValue(v, e.Value)
+		case !ok:
+			e.Value, e.Source, e.Default = v.Default, sourceDefault, true
+			fallthrough
+		default:
+			e.Err = l.checkValue(v, e.Value)
+		}
+
+		if v.Secret && e.Value != "" {
+			e.Value = redacted
+		}
+		explanations[i] = e
+	}
+
+	return explanations, nil
+}
+
+// checkValue reports whether value can be set to the field of v. The value of
+// a file var is the name of the file, so the file is checked instead.
+func (l *loader) checkValue(v Var, value string) error {
+	if v.file {
+		return checkFile(value)
+	}
+	return l.setField(reflect.New(v.Type).Elem(), value)
+}
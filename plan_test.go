@@ -0,0 +1,142 @@
+package env_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// planUnmarshaler is an [env.Unmarshaler] that requires X to be set.
+type planUnmarshaler struct{ X string }
+
+func (u *planUnmarshaler) UnmarshalEnv(p env.Provider) error {
+	var ok bool
+	if u.X, ok = p.LookupEnv("X"); !ok {
+		return errors.New("X is not set")
+	}
+	return nil
+}
+
+func TestPlan(t *testing.T) {
+	t.Run("dry run", func(t *testing.T) {
+		m := env.Map{
+			"HOST":     "localhost",
+			"PASSWORD": "qwerty",
+		}
+
+		var cfg struct {
+			Host     string `env:"HOST"`
+			Port     int    `env:"PORT" default:"8080"`
+			Password string `env:"PASSWORD,secret"`
+		}
+		plan, err := env.Plan(m, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Len[F](t, plan, 3)
+
+		test := func(entry env.PlanEntry, name, value string, isDefault bool) {
+			t.Run(name, func(t *testing.T) {
+				assert.Equal[E](t, entry.Name, name)
+				assert.Equal[E](t, entry.Value, value)
+				assert.Equal[E](t, entry.Default, isDefault)
+			})
+		}
+
+		test(plan[0], "HOST", "localhost", false)
+		test(plan[1], "PORT", "8080", true)
+		test(plan[2], "PASSWORD", "***", false)
+
+		// the struct must be left untouched.
+		assert.Zero[E](t, cfg.Host)
+		assert.Zero[E](t, cfg.Port)
+		assert.Zero[E](t, cfg.Password)
+	})
+
+	t.Run("parsing error", func(t *testing.T) {
+		m := env.Map{"PORT": "-"}
+
+		var cfg struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		}
+		plan, err := env.Plan(m, &cfg)
+		assert.ErrorContains[E](t, err, "parsing int")
+		assert.Len[E](t, plan, 1)
+	})
+
+	t.Run("unmarshaler", func(t *testing.T) {
+		var u planUnmarshaler
+		plan, err := env.Plan(env.Map{"X": "mutated"}, &u)
+		assert.NoErr[F](t, err)
+		assert.Len[E](t, plan, 0)
+		assert.Zero[E](t, u.X) // the struct must be left untouched.
+
+		_, err = env.Plan(env.Map{}, &u)
+		assert.ErrorContains[E](t, err, "X is not set")
+	})
+}
+
+func TestExplain(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.Explain(env.Map{}, nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		p := env.Multi(
+			env.Named("dotenv:.env", env.Map{"DATABASE_HOST": "localhost"}),
+			env.Map{"PORT": "-", "TOKEN": "qwerty"},
+		)
+
+		var cfg struct {
+			Host    string `env:"DB_HOST" deprecated:"DATABASE_HOST"`
+			Port    int    `env:"PORT"`
+			Timeout int    `env:"TIMEOUT" default:"10"`
+			User    string `env:"USER,required"`
+			Token   string `env:"TOKEN,secret"`
+		}
+		explanations, err := env.Explain(p, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Len[F](t, explanations, 5)
+
+		host := explanations[0]
+		assert.Equal[E](t, host.Tried, []string{"DB_HOST", "DATABASE_HOST"})
+		assert.Equal[E](t, host.Value, "localhost")
+		assert.Equal[E](t, host.Source, "dotenv:.env")
+		assert.NoErr[E](t, host.Err)
+
+		port := explanations[1]
+		assert.IsErr[E](t, port.Err, strconv.ErrSyntax)
+
+		timeout := explanations[2]
+		assert.Equal[E](t, timeout.Default, true)
+		assert.Equal[E](t, timeout.Source, "default")
+		assert.NoErr[E](t, timeout.Err)
+
+		user := explanations[3]
+		assert.AsErr[E](t, user.Err, new(*env.NotSetError))
+
+		token := explanations[4]
+		assert.Equal[E](t, token.Value, "***")
+
+		// the struct must be left untouched.
+		assert.Zero[E](t, cfg.Host)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		var cfg struct {
+			Cert io.Reader     `env:"CERT,file"`
+			Key  io.ReadCloser `env:"KEY,file" default:"testdata/missing.pem"`
+		}
+		explanations, err := env.Explain(env.Map{"CERT": "plan_test.go"}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Len[F](t, explanations, 2)
+		assert.NoErr[E](t, explanations[0].Err)
+		assert.IsErr[E](t, explanations[1].Err, os.ErrNotExist)
+	})
+}
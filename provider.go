@@ -1,6 +1,12 @@
 package env
 
-import "os"
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
 
 // Provider represents an entity that is able to provide environment variables.
 type Provider interface {
@@ -15,8 +21,64 @@ type ProviderFunc func(key string) (value string, ok bool)
 // LookupEnv implements the [Provider] interface.
 func (f ProviderFunc) LookupEnv(key string) (string, bool) { return f(key) }
 
-// OS is the main [Provider] that uses [os.LookupEnv].
-var OS Provider = ProviderFunc(os.LookupEnv)
+// Lister is an optional interface that a [Provider] can implement to list the
+// names of all the environment variables it has. It is used by the options
+// that need to know about variables not defined in the struct, e.g.
+// [WithStrictPrefix]. [OS], [Map], and the providers returned by [Named] and
+// [Multi] implement it.
+type Lister interface {
+	// Keys returns the names of all the available environment variables.
+	Keys() []string
+}
+
+// OS is the main [Provider] that uses [os.LookupEnv]. Its name is "os".
+var OS = Named("os", osProvider{})
+
+// defaultProvider is the [Provider] used when no provider is specified.
+var defaultProvider atomic.Value // of providerHolder.
+
+// providerHolder allows storing providers of different types in
+// defaultProvider, which requires consistently typed values.
+type providerHolder struct{ p Provider }
+
+// SetDefaultProvider sets the [Provider] used by [Load] and other functions
+// when no provider is specified (including passing nil to [LoadFrom]). It is
+// useful to install a global provider chain once, e.g. in main, instead of
+// passing it everywhere. If p is nil, the default provider is reset to [OS].
+// It is safe for concurrent use.
+func SetDefaultProvider(p Provider) {
+	if p == nil {
+		p = OS
+	}
+	defaultProvider.Store(providerHolder{p: p})
+}
+
+// DefaultProvider returns the [Provider] used when no provider is specified.
+// See [SetDefaultProvider] for details.
+func DefaultProvider() Provider {
+	if h, ok := defaultProvider.Load().(providerHolder); ok {
+		return h.p
+	}
+	return OS
+}
+
+// osProvider is a [Provider] implementation that uses the process environment.
+type osProvider struct{}
+
+// LookupEnv implements the [Provider] interface.
+func (osProvider) LookupEnv(key string) (string, bool) { return os.LookupEnv(key) }
+
+// Keys implements the [Lister] interface.
+func (osProvider) Keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if key, _, _ := strings.Cut(kv, "="); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
 
 // Map is an in-memory [Provider] implementation useful in tests.
 type Map map[string]string
@@ -26,3 +88,239 @@ func (m Map) LookupEnv(key string) (string, bool) {
 	value, ok := m[key]
 	return value, ok
 }
+
+// Keys implements the [Lister] interface.
+func (m Map) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// source names used when a [Provider] does not report its own name.
+const (
+	sourceEnv     = "env"
+	sourceDefault = "default"
+)
+
+// Named returns a [Provider] that looks up environment variables using p and
+// reports name as their source, e.g. "dotenv:.env". See [WithSources] for
+// details.
+func Named(name string, p Provider) Provider {
+	return namedProvider{name: name, p: p}
+}
+
+// namedProvider is a [Provider] with a name.
+type namedProvider struct {
+	name string
+	p    Provider
+}
+
+// LookupEnv implements the [Provider] interface.
+func (n namedProvider) LookupEnv(key string) (string, bool) { return n.p.LookupEnv(key) }
+
+// Keys implements the [Lister] interface.
+func (n namedProvider) Keys() []string { return listKeys(n.p) }
+
+// lookupEnvSource implements the sourceProvider interface.
+func (n namedProvider) lookupEnvSource(key string) (string, string, bool) {
+	value, ok := n.p.LookupEnv(key)
+	return value, n.name, ok
+}
+
+// Multi returns a [Provider] that looks up environment variables in the
+// provided providers in order, the first one that has the variable wins. If
+// the winning provider is created using [Named], its name is reported as the
+// source of the variable.
+func Multi(ps ...Provider) Provider {
+	return multiProvider(ps)
+}
+
+// multiProvider is a chain of providers.
+type multiProvider []Provider
+
+// LookupEnv implements the [Provider] interface.
+func (m multiProvider) LookupEnv(key string) (string, bool) {
+	value, _, ok := m.lookupEnvSource(key)
+	return value, ok
+}
+
+// Keys implements the [Lister] interface.
+func (m multiProvider) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, p := range m {
+		for _, key := range listKeys(p) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// lookupEnvSource implements the sourceProvider interface.
+func (m multiProvider) lookupEnvSource(key string) (string, string, bool) {
+	for _, p := range m {
+		if value, source, ok := lookupSource(p, key); ok {
+			return value, source, true
+		}
+	}
+	return "", "", false
+}
+
+// Middleware is a function that wraps a [Provider] to extend its behaviour,
+// e.g. with caching, retries, or tracing.
+type Middleware func(Provider) Provider
+
+// Chain wraps p with the provided middlewares. The first middleware is the
+// outermost one, i.e. Chain(p, m1, m2) is equivalent to m1(m2(p)).
+func Chain(p Provider, mws ...Middleware) Provider {
+	for i := len(mws) - 1; i >= 0; i-- {
+		p = mws[i](p)
+	}
+	return p
+}
+
+// sourceProvider is implemented by providers that are able to report the
+// source of a value.
+type sourceProvider interface {
+	lookupEnvSource(key string) (value, source string, ok bool)
+}
+
+// lookupSource retrieves the value of the environment variable named by the key
+// using p and reports its source.
+func lookupSource(p Provider, key string) (string, string, bool) {
+	if sp, ok := p.(sourceProvider); ok {
+		return sp.lookupEnvSource(key)
+	}
+	value, ok := p.LookupEnv(key)
+	return value, sourceEnv, ok
+}
+
+// listKeys returns the names of all the environment variables p has, if it
+// implements the [Lister] interface, or nil otherwise.
+func listKeys(p Provider) []string {
+	if l, ok := p.(Lister); ok {
+		return l.Keys()
+	}
+	return nil
+}
+
+// trackingProvider is a [Provider] that records the looked up keys. It is safe
+// for concurrent use, if the underlying [Provider] is.
+type trackingProvider struct {
+	Provider
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// LookupEnv implements the [Provider] interface.
+func (t *trackingProvider) LookupEnv(key string) (string, bool) {
+	t.markSeen(key)
+	return t.Provider.LookupEnv(key)
+}
+
+// lookupEnvSource implements the sourceProvider interface.
+func (t *trackingProvider) lookupEnvSource(key string) (string, string, bool) {
+	t.markSeen(key)
+	return lookupSource(t.Provider, key)
+}
+
+// markSeen records that the key has been looked up.
+func (t *trackingProvider) markSeen(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seen[key] = true
+}
+
+// unseenKeys returns the sorted names of the environment variables starting
+// with the prefix that have never been looked up.
+func (t *trackingProvider) unseenKeys(prefix string) []string {
+	var keys []string
+	for _, key := range listKeys(t.Provider) {
+		if strings.HasPrefix(key, prefix) && !t.seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupResult is the result of a single [lookupSource] call.
+type lookupResult struct {
+	value  string
+	source string
+	ok     bool
+}
+
+// prefetchedProvider is a [Provider] that serves the results of the lookups
+// performed in advance by [prefetch], falling back to the underlying
+// [Provider] for the other keys.
+type prefetchedProvider struct {
+	Provider
+	results map[string]lookupResult
+}
+
+// LookupEnv implements the [Provider] interface.
+func (p *prefetchedProvider) LookupEnv(key string) (string, bool) {
+	if r, ok := p.results[key]; ok {
+		return r.value, r.ok
+	}
+	return p.Provider.LookupEnv(key)
+}
+
+// lookupEnvSource implements the sourceProvider interface.
+func (p *prefetchedProvider) lookupEnvSource(key string) (string, string, bool) {
+	if r, ok := p.results[key]; ok {
+		return r.value, r.source, r.ok
+	}
+	return lookupSource(p.Provider, key)
+}
+
+// prefetch looks up the variables using up to n concurrent workers, the same
+// way [loader.lookupVar] does it: the deprecated names of a variable are only
+// looked up if the variable itself is not set.
+func prefetch(p Provider, vars []Var, n int) *prefetchedProvider {
+	type lookup struct {
+		key string
+		lookupResult
+	}
+
+	results := make([][]lookup, len(vars))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < n && w < len(vars); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				keys := append([]string{vars[i].Name}, vars[i].Deprecated...)
+				for _, key := range keys {
+					value, source, ok := lookupSource(p, key)
+					results[i] = append(results[i], lookup{key, lookupResult{value, source, ok}})
+					if ok {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range vars {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	pp := prefetchedProvider{Provider: p, results: make(map[string]lookupResult)}
+	for _, lookups := range results {
+		for _, l := range lookups {
+			pp.results[l.key] = l.lookupResult
+		}
+	}
+	return &pp
+}
@@ -0,0 +1,76 @@
+//go:build js && wasm
+
+package env
+
+import (
+	"net/url"
+	"strings"
+	"syscall/js"
+)
+
+// Query returns a [Provider] that looks up environment variables in the query
+// parameters of the URL of the current page (i.e. window.location.search), so
+// that the same config structs can be used in Go programs compiled to WASM and
+// delivered to the browser, where there is no process environment. If a
+// parameter is repeated, its first value is used. The parameters are read once,
+// when Query is called. Its name is "query", see [WithSources] for details.
+// It is only available on js/wasm.
+func Query() Provider {
+	m := make(Map)
+
+	location := js.Global().Get("location")
+	if location.Type() != js.TypeObject {
+		return Named("query", m)
+	}
+
+	values, _ := url.ParseQuery(strings.TrimPrefix(location.Get("search").String(), "?"))
+	for key, vs := range values {
+		m[key] = vs[0]
+	}
+	return Named("query", m)
+}
+
+// LocalStorage returns a [Provider] that looks up environment variables in the
+// window.localStorage of the browser. Unlike [Query], the values are read on
+// each lookup. If the storage is not available, no variables are found. Its
+// name is "localStorage", see [WithSources] for details. It is only available
+// on js/wasm.
+//
+// Combine it with [Query] to let the query parameters override the stored
+// values:
+//
+//	p := env.Multi(env.Query(), env.LocalStorage())
+func LocalStorage() Provider {
+	return Named("localStorage", storageProvider{storage: js.Global().Get("localStorage")})
+}
+
+// storageProvider is a [Provider] implementation that uses a Web Storage
+// object, e.g. window.localStorage.
+type storageProvider struct {
+	storage js.Value
+}
+
+// LookupEnv implements the [Provider] interface.
+func (s storageProvider) LookupEnv(key string) (string, bool) {
+	if s.storage.Type() != js.TypeObject {
+		return "", false
+	}
+	value := s.storage.Call("getItem", key)
+	if value.Type() != js.TypeString {
+		return "", false
+	}
+	return value.String(), true
+}
+
+// Keys implements the [Lister] interface.
+func (s storageProvider) Keys() []string {
+	if s.storage.Type() != js.TypeObject {
+		return nil
+	}
+	n := s.storage.Get("length").Int()
+	keys := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		keys = append(keys, s.storage.Call("key", i).String())
+	}
+	return keys
+}
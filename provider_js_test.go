@@ -0,0 +1,84 @@
+//go:build js && wasm
+
+package env_test
+
+import (
+	"sort"
+	"syscall/js"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// setupBrowser emulates the browser globals used by the providers.
+func setupBrowser(t *testing.T) {
+	js.Global().Call("eval", `
+		globalThis.location = { search: "?HOST=example.com&PORT=8080&PORT=9090" };
+		globalThis.localStorage = (() => {
+			const items = new Map([["PORT", "80"], ["DEBUG", "true"]]);
+			return {
+				get length() { return items.size; },
+				key: (i) => [...items.keys()][i],
+				getItem: (key) => items.has(key) ? items.get(key) : null,
+			};
+		})();
+	`)
+	t.Cleanup(func() {
+		js.Global().Call("eval", `delete globalThis.location; delete globalThis.localStorage;`)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	setupBrowser(t)
+
+	p := env.Query()
+	value, ok := p.LookupEnv("PORT")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, value, "8080")
+
+	_, ok = p.LookupEnv("DEBUG")
+	assert.False[E](t, ok)
+}
+
+func TestLocalStorage(t *testing.T) {
+	setupBrowser(t)
+
+	p := env.LocalStorage()
+	value, ok := p.LookupEnv("DEBUG")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, value, "true")
+
+	_, ok = p.LookupEnv("HOST")
+	assert.False[E](t, ok)
+
+	keys := p.(env.Lister).Keys()
+	sort.Strings(keys)
+	assert.Equal[E](t, keys, []string{"DEBUG", "PORT"})
+}
+
+func TestBrowserProviders(t *testing.T) {
+	setupBrowser(t)
+
+	var cfg struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(env.Multi(env.Query(), env.LocalStorage()), &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Host, "example.com")
+	assert.Equal[E](t, cfg.Port, 8080)
+	assert.True[E](t, cfg.Debug)
+	assert.Equal[E](t, sources, map[string]string{"HOST": "query", "PORT": "query", "DEBUG": "localStorage"})
+
+	t.Run("unavailable", func(t *testing.T) {
+		js.Global().Call("eval", `delete globalThis.location; delete globalThis.localStorage;`)
+		_, ok := env.Query().LookupEnv("HOST")
+		assert.False[E](t, ok)
+		_, ok = env.LocalStorage().LookupEnv("DEBUG")
+		assert.False[E](t, ok)
+	})
+}
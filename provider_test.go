@@ -52,3 +52,88 @@ func TestMap_LookupEnv(t *testing.T) {
 	assert.Equal[E](t, cfg.Bar, 2)
 	assert.Equal[E](t, cfg.Baz, 3)
 }
+
+func TestMulti(t *testing.T) {
+	p := env.Multi(
+		env.Named("first", env.Map{"FOO": "1"}),
+		env.Named("second", env.Map{"FOO": "2", "BAR": "2"}),
+		env.Map{"BAZ": "3"},
+	)
+
+	var cfg struct {
+		Foo int `env:"FOO,required"`
+		Bar int `env:"BAR,required"`
+		Baz int `env:"BAZ,required"`
+	}
+	plan, err := env.Plan(p, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Len[F](t, plan, 3)
+	assert.Equal[E](t, plan[0].Value, "1")
+	assert.Equal[E](t, plan[0].Source, "first")
+	assert.Equal[E](t, plan[1].Source, "second")
+	assert.Equal[E](t, plan[2].Source, "env")
+
+	_, ok := p.LookupEnv("QUX")
+	assert.False[E](t, ok)
+}
+
+func TestLister(t *testing.T) {
+	t.Setenv("LISTER_TEST", "1")
+
+	test := func(name string, p env.Provider, key string) {
+		t.Run(name, func(t *testing.T) {
+			l, ok := p.(env.Lister)
+			assert.True[F](t, ok)
+
+			var found bool
+			for _, k := range l.Keys() {
+				found = found || k == key
+			}
+			assert.True[E](t, found)
+		})
+	}
+
+	test("os", env.OS, "LISTER_TEST")
+	test("map", env.Map{"FOO": "1"}, "FOO")
+	test("named", env.Named("foo", env.Map{"FOO": "1"}), "FOO")
+	test("multi", env.Multi(env.Map{"FOO": "1"}, env.Map{"BAR": "2"}), "BAR")
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	mw := func(name string) env.Middleware {
+		return func(p env.Provider) env.Provider {
+			return env.ProviderFunc(func(key string) (string, bool) {
+				calls = append(calls, name)
+				return p.LookupEnv(key)
+			})
+		}
+	}
+
+	p := env.Chain(env.Map{"FOO": "1"}, mw("first"), mw("second"))
+	value, ok := p.LookupEnv("FOO")
+	assert.Equal[E](t, value, "1")
+	assert.True[E](t, ok)
+	assert.Equal[E](t, calls, []string{"first", "second"})
+}
+
+func TestSetDefaultProvider(t *testing.T) {
+	defer env.SetDefaultProvider(nil)
+
+	env.SetDefaultProvider(env.Map{"PORT": "8080"})
+
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	err := env.LoadFrom(nil, &cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Port, 8080)
+
+	cfg.Port = 0
+	err = env.Load(&cfg)
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Port, 8080)
+
+	env.SetDefaultProvider(nil)
+	assert.Equal[E](t, env.DefaultProvider(), env.OS)
+}
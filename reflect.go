@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
 var (
 	durationType     = reflect.TypeOf(new(time.Duration)).Elem()
 	unmarshalerIface = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+	marshalerIface   = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
 )
 
 // typeOf reports whether v's type is one of the provided types.
@@ -36,8 +38,14 @@ func kindOf(v reflect.Value, kinds ...reflect.Kind) bool {
 // implements reports whether v's type implements one of the provided
 // interfaces.
 func implements(v reflect.Value, ifaces ...reflect.Type) bool {
+	return typeImplements(v.Type(), ifaces...)
+}
+
+// typeImplements reports whether t or a pointer to t implements one of the
+// provided interfaces.
+func typeImplements(t reflect.Type, ifaces ...reflect.Type) bool {
 	for _, iface := range ifaces {
-		if t := v.Type(); t.Implements(iface) || reflect.PtrTo(v.Type()).Implements(iface) {
+		if t.Implements(iface) || reflect.PtrTo(t).Implements(iface) {
 			return true
 		}
 	}
@@ -52,6 +60,14 @@ func structPtr(v reflect.Value) bool {
 // setValue parses s based on v's type/kind and sets v's underlying value to the
 // result.
 func setValue(v reflect.Value, s string) error {
+	// fast path: the predeclared types (e.g. int or string) have no methods,
+	// so there is no need to check for encoding.TextUnmarshaler.
+	if v.Type().PkgPath() == "" {
+		if set := basicSetter(v.Kind()); set != nil {
+			return set(v, s)
+		}
+	}
+
 	switch {
 	case typeOf(v, durationType):
 		return setDuration(v, s)
@@ -72,6 +88,25 @@ func setValue(v reflect.Value, s string) error {
 	}
 }
 
+// basicSetter returns the function to set a value of the provided kind, or nil,
+// if the kind is not a basic one.
+func basicSetter(kind reflect.Kind) func(v reflect.Value, s string) error {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUint
+	case reflect.Float32, reflect.Float64:
+		return setFloat
+	case reflect.Bool:
+		return setBool
+	case reflect.String:
+		return setString
+	default:
+		return nil
+	}
+}
+
 // setInt parses an int value from s and sets v's underlying value to it.
 func setInt(v reflect.Value, s string) error {
 	bits := v.Type().Bits()
@@ -128,7 +163,7 @@ func setDuration(v reflect.Value, s string) error {
 	if err != nil {
 		return fmt.Errorf("parsing duration: %w", err)
 	}
-	v.Set(reflect.ValueOf(d))
+	v.SetInt(int64(d))
 	return nil
 }
 
@@ -153,3 +188,81 @@ func setSlice(v reflect.Value, s []string) error {
 	v.Set(slice)
 	return nil
 }
+
+// formatValue formats v's underlying value as a string that can be parsed back
+// by setValue (or setSlice, using sep as the separator).
+func formatValue(v reflect.Value, sep string) (string, error) {
+	switch {
+	case typeOf(v, durationType):
+		return v.Interface().(time.Duration).String(), nil
+	case implements(v, marshalerIface):
+		return formatMarshaler(v)
+	case kindOf(v, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64):
+		return strconv.FormatInt(v.Int(), 10), nil
+	case kindOf(v, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64):
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case kindOf(v, reflect.Float32, reflect.Float64):
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case kindOf(v, reflect.Bool):
+		return strconv.FormatBool(v.Bool()), nil
+	case kindOf(v, reflect.String):
+		return v.String(), nil
+	case kindOf(v, reflect.Slice) && !implements(v, unmarshalerIface):
+		return formatSlice(v, sep)
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnsupportedType, v.Type())
+	}
+}
+
+// formatDefault formats v's underlying value the same way fmt.Sprintf("%v")
+// does. The common types are formatted directly to avoid boxing v into an
+// interface, which allocates.
+func formatDefault(v reflect.Value) string {
+	if typeOf(v, durationType) {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Type().NumMethod() > 0 {
+		// e.g. fmt.Stringer, which is respected by fmt.
+		return fmt.Sprintf("%v", v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+// formatMarshaler calls v's MarshalText method and returns the result.
+func formatMarshaler(v reflect.Value) (string, error) {
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok {
+		// the method has a pointer receiver.
+		m = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+	text, err := m.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("marshaling text: %w", err)
+	}
+	return string(text), nil
+}
+
+// formatSlice formats each element of the v slice and joins the results using
+// sep as the separator.
+func formatSlice(v reflect.Value, sep string) (string, error) {
+	s := make([]string, v.Len())
+	for i := range s {
+		var err error
+		if s[i], err = formatValue(v.Index(i), sep); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(s, sep), nil
+}
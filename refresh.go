@@ -0,0 +1,166 @@
+package env
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Refresh periodically re-resolves the environment variables that have the
+// `ttl` tag (e.g. `env:"DB_PASSWORD,secret" ttl:"15m"`) using the specified
+// [Provider] and updates the corresponding fields of dst. It is intended for
+// secrets that are rotated by a secret manager. If some values change,
+// onChange is called with the list of changes (see [Diff]), e.g. to rebuild a
+// database connection pool. dst must be a non-nil struct pointer, usually
+// loaded with [LoadFrom] beforehand, otherwise Refresh returns
+// [ErrInvalidArgument].
+//
+// Refresh blocks until ctx is done and returns ctx.Err(), so it should be run
+// in a separate goroutine. Since the fields are updated in the background, use
+// the [WithLocker] option to synchronize access to the struct. If a variable is
+// not set or cannot be parsed, its field keeps the current value. If no
+// variables have the `ttl` tag, Refresh returns nil immediately.
+func Refresh(ctx context.Context, p Provider, dst any, onChange func([]FieldChange), opts ...Option) error {
+	rv := reflect.ValueOf(dst)
+	if !structPtr(rv) {
+		return ErrInvalidArgument
+	}
+
+	l := newLoader(p, opts...)
+
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return err
+	}
+
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+
+	vars = filterVars(vars, func(v Var) bool { return v.TTL > 0 && !v.opaque() })
+	if len(vars) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	due := make([]time.Time, len(vars))
+	for i, v := range vars {
+		due[i] = now.Add(v.TTL)
+	}
+
+	for {
+		next := due[0]
+		for _, t := range due[1:] {
+			if t.Before(next) {
+				next = t
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case now = <-timer.C:
+		}
+
+		var expired []int
+		for i := range vars {
+			if !due[i].After(now) {
+				expired = append(expired, i)
+				due[i] = now.Add(vars[i].TTL)
+			}
+		}
+
+		if changes := l.refreshVars(vars, expired); len(changes) > 0 && onChange != nil {
+			onChange(changes)
+		}
+	}
+}
+
+// refreshVars re-resolves the vars with the provided indexes and updates the
+// fields whose values have changed. It returns the list of changes.
+func (l *loader) refreshVars(vars []Var, indexes []int) []FieldChange {
+	type update struct {
+		v     Var
+		value reflect.Value
+	}
+
+	// the referenced variables may have been rotated since the previous pass.
+	l.expanded = nil
+
+	var updates []update
+	for _, i := range indexes {
+		v := vars[i]
+		value, _, ok, err := l.lookupVar(v)
+		if err != nil || !ok {
+			continue
+		}
+		field := reflect.New(v.Type).Elem()
+		if err := l.setField(field, value); err != nil {
+			continue
+		}
+		updates = append(updates, update{v: v, value: field})
+	}
+
+	if l.locker != nil {
+		l.locker.Lock()
+		defer l.locker.Unlock()
+	}
+
+	var changes []FieldChange
+	for _, u := range updates {
+		if reflect.DeepEqual(u.v.field.Interface(), u.value.Interface()) {
+			continue
+		}
+		changes = append(changes, newFieldChange(u.v, u.v.field, u.value))
+		u.v.field.Set(u.value)
+	}
+
+	return changes
+}
+
+// Reload re-reads environment variables into the provided struct using the
+// default [Provider] as their source, but overwrites only the fields whose
+// values were previously loaded from the environment. The fields that got
+// their values from the struct initialization or the `default` tag (i.e. set
+// by code) are left untouched. sources must be the map previously populated by
+// the [WithSources] option, it is updated with the new sources. It enables safe
+// periodic refreshing of long-lived config structs:
+//
+//	sources := make(map[string]string)
+//	if err := env.Load(&cfg, env.WithSources(sources)); err != nil {
+//		// handle error
+//	}
+//	// later:
+//	if err := env.Reload(&cfg, sources, env.WithLocker(&mu)); err != nil {
+//		// handle error
+//	}
+//
+// If the [WithLocker] option is provided, the lock is held while reloading.
+func Reload(dst any, sources map[string]string, opts ...Option) error {
+	return ReloadFrom(nil, dst, sources, opts...)
+}
+
+// ReloadFrom is like [Reload], but uses the specified [Provider] as the source
+// of environment variables.
+func ReloadFrom(p Provider, dst any, sources map[string]string, opts ...Option) error {
+	l := newLoader(p, opts...)
+	l.sources = sources
+
+	filter := l.filter
+	l.filter = func(v Var) bool {
+		if filter != nil && !filter(v) {
+			return false
+		}
+		source, ok := sources[v.Name]
+		return ok && source != sourceDefault
+	}
+
+	if l.locker != nil {
+		l.locker.Lock()
+		defer l.locker.Unlock()
+	}
+
+	return l.loadVars(dst)
+}
@@ -0,0 +1,101 @@
+package env
+
+import (
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// CGI returns a [Provider] that looks up the CGI meta-variables of the provided
+// HTTP request (see RFC 3875), so that request-scoped configuration (e.g. in
+// gateways) can be loaded into structs the same way the process environment
+// is. The following variables are provided:
+//
+//   - REQUEST_METHOD, SERVER_PROTOCOL, QUERY_STRING, PATH_INFO, REQUEST_URI
+//   - SERVER_NAME, SERVER_PORT, REMOTE_ADDR, REMOTE_PORT
+//   - CONTENT_TYPE, CONTENT_LENGTH (if set)
+//   - HTTPS ("on", if the request is received over TLS)
+//   - HTTP_*: the request headers, e.g. HTTP_USER_AGENT for User-Agent; the
+//     repeated headers are joined with ", "; the Proxy header is skipped, so
+//     that a client cannot set HTTP_PROXY (see https://httpoxy.org)
+//
+// The variables are read once, when CGI is called. Its name is "cgi", see
+// [WithSources] for details.
+func CGI(r *http.Request) Provider {
+	m := Map{
+		"REQUEST_METHOD":  r.Method,
+		"SERVER_PROTOCOL": r.Proto,
+		"QUERY_STRING":    r.URL.RawQuery,
+		"PATH_INFO":       r.URL.Path,
+		"REQUEST_URI":     r.URL.RequestURI(),
+	}
+
+	port := "80"
+	if r.TLS != nil {
+		m["HTTPS"] = "on"
+		port = "443"
+	}
+	if host, p, err := net.SplitHostPort(r.Host); err == nil {
+		m["SERVER_NAME"], m["SERVER_PORT"] = host, p
+	} else {
+		m["SERVER_NAME"], m["SERVER_PORT"] = r.Host, port
+	}
+
+	if host, p, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		m["REMOTE_ADDR"], m["REMOTE_PORT"] = host, p
+	} else if r.RemoteAddr != "" {
+		m["REMOTE_ADDR"] = r.RemoteAddr
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		m["CONTENT_TYPE"] = ct
+	}
+	if r.ContentLength > 0 {
+		m["CONTENT_LENGTH"] = strconv.FormatInt(r.ContentLength, 10)
+	}
+
+	for key, values := range r.Header {
+		if key == "Content-Type" || key == "Content-Length" {
+			// already provided as CONTENT_TYPE and CONTENT_LENGTH.
+			continue
+		}
+		if key == "Proxy" {
+			// HTTP_PROXY is commonly used to configure the outgoing proxy, see
+			// https://httpoxy.org. net/http/cgi skips it for the same reason.
+			continue
+		}
+		m["HTTP_"+headerToName(key)] = strings.Join(values, ", ")
+	}
+
+	return Named("cgi", m)
+}
+
+// Header returns a [Provider] that looks up environment variables in the
+// headers of the provided HTTP request starting with the prefix, e.g. with the
+// "X-Config-" prefix, DB_HOST is looked up in the X-Config-Db-Host header. The
+// underscores in the names of the variables correspond to the dashes in the
+// names of the headers, and the case is ignored. If a header is repeated, its
+// first value is used. The headers are read once, when Header is called. Its
+// name is "header", see [WithSources] for details.
+func Header(r *http.Request, prefix string) Provider {
+	prefix = textproto.CanonicalMIMEHeaderKey(prefix)
+
+	m := make(Map)
+	for key, values := range r.Header {
+		// the keys are canonicalized, so compare them case-insensitively.
+		if len(key) <= len(prefix) || !strings.EqualFold(key[:len(prefix)], prefix) || len(values) == 0 {
+			continue
+		}
+		m[headerToName(key[len(prefix):])] = values[0]
+	}
+
+	return Named("header", m)
+}
+
+// headerToName converts the name of an HTTP header to the name of an
+// environment variable, e.g. User-Agent becomes USER_AGENT.
+func headerToName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
@@ -0,0 +1,93 @@
+package env_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestCGI(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "https://example.com:8443/api/users?limit=10", strings.NewReader("{}"))
+	r.RemoteAddr = "192.0.2.1:54321"
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+	r.Header.Set("Proxy", "http://attacker.example.com") // must not become HTTP_PROXY.
+
+	p := env.CGI(r)
+	assert.ElementsMatch[E](t, p.(env.Lister).Keys(), env.Map{
+		"REQUEST_METHOD":  "POST",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+		"QUERY_STRING":    "limit=10",
+		"PATH_INFO":       "/api/users",
+		"REQUEST_URI":     "/api/users?limit=10",
+		"SERVER_NAME":     "example.com",
+		"SERVER_PORT":     "8443",
+		"REMOTE_ADDR":     "192.0.2.1",
+		"REMOTE_PORT":     "54321",
+		"CONTENT_TYPE":    "application/json",
+		"CONTENT_LENGTH":  "2",
+		"HTTPS":           "on",
+		"HTTP_USER_AGENT": "curl/8.0",
+		"HTTP_ACCEPT":     "text/html, application/json",
+	}.Keys())
+
+	_, ok := p.LookupEnv("HTTP_PROXY")
+	assert.False[E](t, ok)
+
+	var cfg struct {
+		Method string `env:"REQUEST_METHOD"`
+		Port   int    `env:"SERVER_PORT"`
+		Accept string `env:"HTTP_ACCEPT"`
+		HTTPS  string `env:"HTTPS"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(p, &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Method, "POST")
+	assert.Equal[E](t, cfg.Port, 8443)
+	assert.Equal[E](t, cfg.Accept, "text/html, application/json")
+	assert.Equal[E](t, cfg.HTTPS, "on")
+	assert.Equal[E](t, sources["REQUEST_METHOD"], "cgi")
+
+	t.Run("default port", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		port, _ := env.CGI(r).LookupEnv("SERVER_PORT")
+		assert.Equal[E](t, port, "80")
+
+		r.TLS = new(tls.ConnectionState)
+		port, _ = env.CGI(r).LookupEnv("SERVER_PORT")
+		assert.Equal[E](t, port, "443")
+	})
+}
+
+func TestHeader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Config-Db-Host", "db.example.com")
+	r.Header.Add("X-Config-Db-Port", "5432")
+	r.Header.Add("X-Config-Db-Port", "5433")
+	r.Header.Set("X-Config-", "empty name")
+	r.Header.Set("X-Request-Id", "42")
+
+	var cfg struct {
+		Host string `env:"DB_HOST"`
+		Port int    `env:"DB_PORT"`
+		ID   string `env:"REQUEST_ID"`
+	}
+	sources := make(map[string]string)
+	err := env.LoadFrom(env.Header(r, "x-config-"), &cfg, env.WithSources(sources))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, cfg.Host, "db.example.com")
+	assert.Equal[E](t, cfg.Port, 5432)
+	assert.Zero[E](t, cfg.ID)
+	assert.Equal[E](t, sources, map[string]string{"DB_HOST": "header", "DB_PORT": "header", "REQUEST_ID": "default"})
+
+	assert.ElementsMatch[E](t, env.Header(r, "X-Config-").(env.Lister).Keys(), []string{"DB_HOST", "DB_PORT"})
+}
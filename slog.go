@@ -0,0 +1,59 @@
+//go:build go1.21
+
+package env
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger configures [Load]/[LoadFrom] to log each resolved environment
+// variable at the debug level, including its source, whether the default value
+// is used, and the value itself (redacted, if the variable is marked as
+// secret). It simplifies debugging configuration issues in production.
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *loader) {
+		l.resolveHooks = append(l.resolveHooks, func(e PlanEntry) {
+			logger.LogAttrs(context.Background(), slog.LevelDebug, "env: variable resolved",
+				slog.String("name", e.Name),
+				slog.String("field", e.Field),
+				slog.String("source", e.Source),
+				slog.Bool("default", e.Default),
+				slog.String("value", e.Value),
+			)
+		})
+	}
+}
+
+// Redacted returns an [slog.LogValuer] that renders the provided config as a
+// group of attributes, one per environment variable, with the values of the
+// variables marked as secret redacted. It respects the same options as
+// [Marshal] does:
+//
+//	slog.Info("config loaded", "config", env.Redacted(&cfg))
+//
+// If the config cannot be marshaled, the error is rendered instead.
+func Redacted(src any, opts ...Option) slog.LogValuer {
+	return redactedValuer{src: src, opts: opts}
+}
+
+// redactedValuer is an [slog.LogValuer] implementation returned by [Redacted].
+type redactedValuer struct {
+	src  any
+	opts []Option
+}
+
+// LogValue implements the [slog.LogValuer] interface.
+func (r redactedValuer) LogValue() slog.Value {
+	vars, err := marshalVars(r.src, r.opts...)
+	if err != nil {
+		return slog.StringValue(err.Error())
+	}
+
+	attrs := make([]slog.Attr, len(vars))
+	for i, v := range vars {
+		attrs[i] = slog.String(v.name, v.redacted())
+	}
+
+	return slog.GroupValue(attrs...)
+}
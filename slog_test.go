@@ -0,0 +1,71 @@
+//go:build go1.21
+
+package env_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// newTestLogger creates a new text logger without timestamps writing to w.
+func newTestLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	const output = `level=DEBUG msg="env: variable resolved" name=DB_HOST field=Host source=env default=false value=localhost
+level=DEBUG msg="env: variable resolved" name=DB_PORT field=Port source=default default=true value=5432
+level=DEBUG msg="env: variable resolved" name=DB_PASSWORD field=Password source=env default=false value=***
+`
+	m := env.Map{
+		"DB_HOST":     "localhost",
+		"DB_PASSWORD": "qwerty",
+	}
+
+	var cfg struct {
+		Host     string `env:"DB_HOST"`
+		Port     int    `env:"DB_PORT" default:"5432"`
+		Password string `env:"DB_PASSWORD,secret"`
+	}
+
+	var buf bytes.Buffer
+	err := env.LoadFrom(m, &cfg, env.WithLogger(newTestLogger(&buf)))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), output)
+	assert.Equal[E](t, cfg.Password, "qwerty")
+}
+
+func TestRedacted(t *testing.T) {
+	const output = "level=INFO msg=config config.DB_USER=admin config.DB_PASSWORD=***\n"
+
+	cfg := struct {
+		User     string `env:"DB_USER"`
+		Password string `env:"DB_PASSWORD,secret"`
+	}{
+		User:     "admin",
+		Password: "qwerty",
+	}
+
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	logger.Info("config", "config", env.Redacted(&cfg))
+	assert.Equal[E](t, buf.String(), output)
+
+	buf.Reset()
+	logger.Info("config", "config", env.Redacted(nil))
+	assert.Equal[E](t, buf.String(), `level=INFO msg=config config="env: argument must be a non-nil struct pointer"`+"\n")
+}
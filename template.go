@@ -0,0 +1,182 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// defaultTemplate is a `default` tag referencing other struct fields, e.g.
+// `env:"URL,template" default:"http://{{.Host}}:{{.Port}}"`. It is executed
+// with the struct passed to [Load]/[LoadFrom] as the data, after the
+// referenced fields are set.
+type defaultTemplate struct {
+	tmpl *template.Template
+	refs []string // the paths of the referenced fields, e.g. "DB.Port".
+}
+
+// parseDefaultTemplate parses the `default` tag of the variable named by name,
+// which is marked with the template option.
+func parseDefaultTemplate(name, value string) (*defaultTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("env: invalid default template of %s: %w", name, err)
+	}
+
+	var refs []string
+	templateRefs(tmpl.Root, &refs)
+	return &defaultTemplate{tmpl: tmpl, refs: refs}, nil
+}
+
+// templateRefs appends the paths of the fields referenced by node to refs. The
+// references inside range and with actions are relative to a different dot,
+// so they are over-approximated, which is harmless for ordering.
+func templateRefs(node parse.Node, refs *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, node := range n.Nodes {
+			templateRefs(node, refs)
+		}
+	case *parse.ActionNode:
+		templateRefs(n.Pipe, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			templateRefs(cmd, refs)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			templateRefs(arg, refs)
+		}
+	case *parse.FieldNode:
+		*refs = append(*refs, strings.Join(n.Ident, "."))
+	case *parse.VariableNode:
+		// $.Host is the same as .Host at the top level.
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			*refs = append(*refs, strings.Join(n.Ident[1:], "."))
+		}
+	case *parse.IfNode:
+		templateRefs(&n.BranchNode, refs)
+	case *parse.RangeNode:
+		templateRefs(&n.BranchNode, refs)
+	case *parse.WithNode:
+		templateRefs(&n.BranchNode, refs)
+	case *parse.BranchNode:
+		templateRefs(n.Pipe, refs)
+		templateRefs(n.List, refs)
+		templateRefs(n.ElseList, refs)
+	}
+}
+
+// resolveTemplates sets the variables using their default templates executed
+// with root as the data. The variables referenced by a template are resolved
+// before the template itself; a reference cycle is reported as an error.
+func (l *loader) resolveTemplates(root reflect.Value, pending []Var) error {
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[string]int, len(pending))
+
+	var resolve func(v Var, stack []string) error
+	resolve = func(v Var, stack []string) error {
+		switch state[v.Field] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("env: default template cycle: %s", strings.Join(stack, " -> "))
+		}
+		state[v.Field] = visiting
+
+		for _, ref := range v.tmpl.refs {
+			for _, dep := range pending {
+				if fieldsOverlap(ref, dep.Field) {
+					if err := resolve(dep, append(stack[:len(stack):len(stack)], dep.Field)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		var sb strings.Builder
+		if err := v.tmpl.tmpl.Execute(&sb, root.Interface()); err != nil {
+			return &ParseError{Name: v.Name, Err: err}
+		}
+		if err := l.setVar(v, sb.String(), sourceDefault, true); err != nil {
+			return err
+		}
+
+		state[v.Field] = done
+		return nil
+	}
+
+	for _, v := range pending {
+		if err := resolve(v, []string{v.Field}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderDefaults executes the default templates of the variables parsed from
+// dst, as if dst were loaded from p, i.e. with the values the referenced fields
+// would be set to. It returns the results keyed by the names of the variables.
+// The options affecting the parsing (e.g. [WithSliceSeparator]) are taken from
+// base, but dst itself is left untouched. The required variables that are not
+// set are ignored, so that the templates referencing them are still executed.
+func renderDefaults(p Provider, dst any, base *loader) (map[string]string, error) {
+	rendered := make(map[string]string)
+	l := &loader{
+		provider:       p,
+		prefix:         base.prefix,
+		sliceSep:       base.sliceSep,
+		expansionStyle: base.expansionStyle,
+		revealSecrets:  true,
+		dryRun:         true,
+	}
+	l.resolveHooks = append(l.resolveHooks, func(e PlanEntry) {
+		if e.tmpl != nil && e.Default {
+			rendered[e.Name] = e.Value
+		}
+	})
+
+	var notSetErr *NotSetError
+	if err := l.loadVars(dst); err != nil && !errors.As(err, &notSetErr) {
+		return rendered, err
+	}
+	return rendered, nil
+}
+
+// setRenderedDefaults replaces the defaults of vars with the results of their
+// templates reported by renderDefaults.
+func setRenderedDefaults(vars []Var, rendered map[string]string) {
+	for i, v := range vars {
+		if value, ok := rendered[v.Name]; ok {
+			vars[i].Default = value
+		}
+	}
+}
+
+// hasTemplates reports whether any of vars has a default template.
+func hasTemplates(vars []Var) bool {
+	for _, v := range vars {
+		if v.tmpl != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsOverlap reports whether the field paths a and b are the same or one of
+// them is nested in the other, e.g. "DB" and "DB.Port".
+func fieldsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
@@ -0,0 +1,144 @@
+package env_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestDefaultTemplate(t *testing.T) {
+	type config struct {
+		// URL is declared before the fields it references on purpose.
+		URL string `env:"URL,template" default:"{{.Scheme}}://{{.DB.Host}}:{{.DB.Port}}"`
+		DB  struct {
+			Host string `env:"DB_HOST" default:"localhost"`
+			Port int    `env:"DB_PORT" default:"5432"`
+		}
+		Scheme string `env:"SCHEME,template" default:"{{if eq .DB.Port 443}}https{{else}}http{{end}}"`
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		var cfg config
+		sources := make(map[string]string)
+		err := env.LoadFrom(env.Map{"DB_HOST": "db.example.com"}, &cfg, env.WithSources(sources))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.URL, "http://db.example.com:5432")
+		assert.Equal[E](t, cfg.Scheme, "http")
+		assert.Equal[E](t, sources["URL"], "default")
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		var cfg config
+		m := env.Map{"DB_PORT": "443", "URL": "https://example.com"}
+		err := env.LoadFrom(m, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.URL, "https://example.com")
+		assert.Equal[E](t, cfg.Scheme, "https")
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		var cfg config
+		plan, err := env.Plan(env.Map{}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Len[E](t, plan, 4)
+		assert.Equal[E](t, plan[3].Name, "URL")
+		assert.Equal[E](t, plan[3].Value, "http://localhost:5432")
+		assert.True[E](t, plan[3].Default)
+		assert.Zero[E](t, cfg.URL)
+	})
+
+	t.Run("describe", func(t *testing.T) {
+		var cfg config
+		vars, err := env.Describe(&cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, vars[0].Name, "APP_URL")
+		assert.Equal[E](t, vars[0].Default, "http://localhost:5432")
+		assert.Equal[E](t, vars[3].Default, "http")
+		assert.Zero[E](t, cfg.URL)
+
+		var buf bytes.Buffer
+		err = env.PrintUsage(&buf, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Contains[E](t, buf.String(), "default http://localhost:5432")
+
+		buf.Reset()
+		err = env.LoadFrom(env.Map{"DB_PORT": "-"}, &cfg, env.WithUsageOnError(&buf))
+		assert.AsErr[F](t, err, new(*env.ParseError))
+		assert.Contains[E](t, buf.String(), "default http://localhost:5432")
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		var cfg config
+		explanations, err := env.Explain(env.Map{"DB_PORT": "443"}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, explanations[0].Value, "https://localhost:443")
+		assert.True[E](t, explanations[0].Default)
+		assert.NoErr[E](t, explanations[0].Err)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		var cfg struct {
+			A string `env:"A,template" default:"{{.B}}"`
+			B string `env:"B,template" default:"{{.C}}"`
+			C string `env:"C,template" default:"{{$.A}}"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.ErrorContains[E](t, err, "env: default template cycle: A -> B -> C -> A")
+
+		// the cycle is broken if one of the variables is set.
+		err = env.LoadFrom(env.Map{"B": "b"}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.A, "b")
+		assert.Equal[E](t, cfg.C, "b")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		var cfg struct {
+			URL string `env:"URL,template" default:"{{.Host"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.ErrorContains[E](t, err, "env: invalid default template of URL")
+	})
+
+	t.Run("execution error", func(t *testing.T) {
+		var cfg struct {
+			URL string `env:"URL,template" default:"{{.Unknown}}"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		var parseErr *env.ParseError
+		assert.AsErr[F](t, err, &parseErr)
+		assert.Equal[E](t, parseErr.Name, "URL")
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		var cfg struct {
+			Host  string `env:"HOST" default:"localhost"`
+			Greet string `env:"GREET" default:"Hello, {{.Host}}!"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Greet, "Hello, {{.Host}}!")
+	})
+
+	t.Run("missing default", func(t *testing.T) {
+		var cfg struct {
+			URL string `env:"URL,template"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.IsErr[E](t, err, env.ErrInvalidTagOption)
+		assert.ErrorContains[E](t, err, "URL requires the `default` tag")
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		var cfg struct {
+			Host string           `env:"HOST"`
+			URL  env.Lazy[string] `env:"URL,lazy,template" default:"{{.Host}}"`
+		}
+		err := env.LoadFrom(env.Map{}, &cfg)
+		assert.True[E](t, errors.Is(err, env.ErrInvalidTagOption))
+	})
+}
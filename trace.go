@@ -0,0 +1,85 @@
+package env
+
+import "time"
+
+// Trace is a set of hooks to run at various stages of [Load]/[LoadFrom]. Any
+// particular hook may be nil. It is intended to be used for instrumentation,
+// e.g. to record OpenTelemetry spans and metrics, without making this package
+// depend on any telemetry library:
+//
+//	var span trace.Span
+//	t := &env.Trace{
+//		LoadStart: func() {
+//			_, span = tracer.Start(ctx, "env.Load")
+//		},
+//		Lookup: func(key string, found bool, d time.Duration) {
+//			lookupDuration.Record(ctx, d.Seconds(), metric.WithAttributes(attribute.Bool("found", found)))
+//		},
+//		LoadDone: func(err error, d time.Duration) {
+//			if err != nil {
+//				span.RecordError(err)
+//			}
+//			span.End()
+//		},
+//	}
+//	if err := env.Load(&cfg, env.WithTrace(t)); err != nil {
+//		// handle error
+//	}
+type Trace struct {
+	// LoadStart is called before loading starts.
+	LoadStart func()
+	// LoadDone is called after loading is finished with the resulting error,
+	// if any, and the total duration.
+	LoadDone func(err error, d time.Duration)
+	// Lookup is called after each [Provider] lookup with the key, whether the
+	// variable was found, and the duration of the lookup.
+	Lookup func(key string, found bool, d time.Duration)
+}
+
+// WithTrace configures [Load]/[LoadFrom] to run the hooks of the provided
+// [Trace]. See its documentation for details.
+func WithTrace(t *Trace) Option {
+	return func(l *loader) { l.trace = t }
+}
+
+// startTrace runs the LoadStart hook, wraps the loader's provider to trace
+// lookups, and returns a function that runs the LoadDone hook.
+func (l *loader) startTrace() func(err error) {
+	start := time.Now()
+	if l.trace.LoadStart != nil {
+		l.trace.LoadStart()
+	}
+
+	if l.trace.Lookup != nil {
+		l.provider = tracedProvider{p: l.provider, hook: l.trace.Lookup}
+	}
+
+	return func(err error) {
+		if l.trace.LoadDone != nil {
+			l.trace.LoadDone(err, time.Since(start))
+		}
+	}
+}
+
+// tracedProvider is a [Provider] that runs a hook after each lookup.
+type tracedProvider struct {
+	p    Provider
+	hook func(key string, found bool, d time.Duration)
+}
+
+// LookupEnv implements the [Provider] interface.
+func (t tracedProvider) LookupEnv(key string) (string, bool) {
+	value, _, ok := t.lookupEnvSource(key)
+	return value, ok
+}
+
+// Keys implements the [Lister] interface.
+func (t tracedProvider) Keys() []string { return listKeys(t.p) }
+
+// lookupEnvSource implements the sourceProvider interface.
+func (t tracedProvider) lookupEnvSource(key string) (string, string, bool) {
+	start := time.Now()
+	value, source, ok := lookupSource(t.p, key)
+	t.hook(key, ok, time.Since(start))
+	return value, source, ok
+}
@@ -0,0 +1,54 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+func TestWithTrace(t *testing.T) {
+	var (
+		started bool
+		lookups []string
+		loadErr error
+	)
+
+	tr := &env.Trace{
+		LoadStart: func() { started = true },
+		Lookup: func(key string, found bool, d time.Duration) {
+			if found {
+				lookups = append(lookups, key)
+			}
+		},
+		LoadDone: func(err error, d time.Duration) { loadErr = err },
+	}
+
+	var cfg struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,required"`
+	}
+	err := env.LoadFrom(env.Map{"HOST": "localhost"}, &cfg, env.WithTrace(tr))
+	assert.AsErr[F](t, err, new(*env.NotSetError))
+	assert.True[E](t, started)
+	assert.Equal[E](t, lookups, []string{"HOST"})
+	assert.Equal[E](t, loadErr, err)
+
+	t.Run("lazy", func(t *testing.T) {
+		lookups = nil
+
+		var cfg struct {
+			Token env.Lazy[string] `env:"TOKEN,lazy"`
+		}
+		err := env.LoadFrom(env.Map{"TOKEN": "qwerty"}, &cfg, env.WithTrace(tr))
+		assert.NoErr[F](t, err)
+
+		// the lookup happens after the load is done, so it is not traced.
+		token, err := cfg.Token.Get()
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, token, "qwerty")
+		assert.Equal[E](t, len(lookups), 0)
+	})
+}
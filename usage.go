@@ -3,28 +3,146 @@ package env
 import (
 	"fmt"
 	"io"
+	"os"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"text/template"
+	"time"
 )
 
 // Var contains information about the environment variable parsed from a struct
 // field. It is exported as a part of the [Usage] function signature.
 type Var struct {
-	Name     string       // Name is the full name of the variable, including prefix.
-	Type     reflect.Type // Type is the variable's type.
-	Desc     string       // Desc is an optional description parsed from the `desc` tag.
-	Default  string       // Default is the default value of the variable. If the variable is marked as required, it will be empty.
-	Required bool         // Required is true, if the variable is marked as required.
-	Expand   bool         // Expand is true, if the variable is marked to be expanded with [os.Expand].
+	Name       string        // Name is the full name of the variable, including prefix.
+	Type       reflect.Type  // Type is the variable's type.
+	Desc       string        // Desc is an optional description parsed from the `desc` tag.
+	Example    string        // Example is an optional example value parsed from the `example` tag.
+	Default    string        // Default is the default value of the variable. If the variable is marked as required, it will be empty.
+	Required   bool          // Required is true, if the variable is marked as required.
+	Expand     bool          // Expand is true, if the variable is marked to be expanded with [os.Expand].
+	Secret     bool          // Secret is true, if the variable is marked as secret.
+	Group      string        // Group is an optional group parsed from the `group` tag.
+	Deprecated []string      // Deprecated is a list of the previous names of the variable, including prefix, parsed from the `deprecated` tag.
+	TTL        time.Duration // TTL is an optional refresh interval parsed from the `ttl` tag, see [Refresh].
+	Field      string        // Field is the path of the original struct field, e.g. "DB.Port".
 
-	field reflect.Value // the original struct field.
+	field reflect.Value     // the original struct field.
+	tag   reflect.StructTag // the original struct field's tag.
+	lazy  bool              // whether the field is of type [Lazy].
+	file  bool              // whether the field is a reader of the file named by the value.
+	tmpl  *defaultTemplate  // the `default` tag referencing other fields, if any.
+}
+
+// opaque reports whether the value of the field cannot be formatted, e.g. it
+// is of type [Lazy], so the helpers reading the current values ignore it.
+func (v Var) opaque() bool { return v.lazy || v.file }
+
+// Describe parses environment variables from the fields of the provided struct
+// without loading them. The struct tags are parsed the same way [Load] does it,
+// and the options affecting the metadata (e.g. [WithPrefix]) are respected. dst
+// must be a non-nil struct pointer, otherwise Describe returns
+// [ErrInvalidArgument]. It is useful to build external tools, e.g. docs
+// generators or validators. The default templates (see the template option)
+// are reported executed, as if no environment variables were set.
+//
+// The functions documenting or auditing the variables of a struct (e.g.
+// [PrintUsage], [WriteMarkdown], or [AuditDeprecated]) parse it the same way,
+// but also respect [WithFilter], and return [ErrInvalidArgument] as well.
+func Describe(dst any, opts ...Option) ([]Var, error) {
+	rv := reflect.ValueOf(dst)
+	if !structPtr(rv) {
+		return nil, ErrInvalidArgument
+	}
+
+	l := newLoader(nil, opts...)
+	vars, err := l.parseVars(rv.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if hasTemplates(vars) {
+		rendered, err := renderDefaults(Map{}, dst, l)
+		if err != nil {
+			return nil, err
+		}
+		setRenderedDefaults(vars, rendered)
+	}
+
+	return vars, nil
+}
+
+// PrintUsage writes a usage message documenting the environment variables
+// parsed from the fields of the provided struct to w, using the [Usage]
+// function, i.e. the equivalent of -help for services configured via
+// environment variables. Unlike [WithUsageOnError], it does not require an
+// error to occur. See [Describe] for how dst is parsed.
+func PrintUsage(w io.Writer, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	Usage(w, newLoader(nil, opts...).usageVars(vars))
+	return nil
+}
+
+// describeVars is like [Describe], but also applies the filter configured by
+// [WithFilter], if any. The default values taken from the current values of
+// the fields are formatted so that they can be loaded back, e.g. slices are
+// joined using the separator configured by [WithSliceSeparator].
+func describeVars(dst any, opts ...Option) ([]Var, error) {
+	vars, err := Describe(dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLoader(nil, opts...)
+	if l.filter != nil {
+		vars = filterVars(vars, l.filter)
+	}
+
+	for i, v := range vars {
+		if _, ok := v.tag.Lookup("default"); ok || v.Required || v.opaque() {
+			continue
+		}
+		if vars[i].Default, err = formatValue(v.field, l.sliceSep); err != nil {
+			return nil, err
+		}
+	}
+
+	return vars, nil
+}
+
+// usageVars sorts and filters vars to be documented in the usage message
+// according to the [WithUsageOrder] and [WithUsageRequiredOnly] options.
+func (l *loader) usageVars(vars []Var) []Var {
+	if l.usageRequiredOnly {
+		vars = filterVars(vars, func(v Var) bool { return v.Required })
+	} else {
+		vars = append([]Var(nil), vars...)
+	}
+
+	switch l.usageOrder {
+	case OrderAlphabetical:
+		sort.SliceStable(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	case OrderGroup:
+		sort.SliceStable(vars, func(i, j int) bool { return vars[i].Group < vars[j].Group })
+	}
+
+	return vars
 }
 
 // Usage prints a usage message documenting all defined environment variables.
 // It will be called by [Load]/[LoadFrom] if the [WithUsageOnError] option is
 // provided and an error occurs while loading environment variables. It is
 // exported as a variable, so it can be changed to a custom implementation.
-var Usage = func(w io.Writer, vars []Var) {
+var Usage = defaultUsage
+
+// defaultUsage is the default implementation of [Usage].
+func defaultUsage(w io.Writer, vars []Var) {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	defer tw.Flush()
 
@@ -45,3 +163,179 @@ var Usage = func(w io.Writer, vars []Var) {
 		fmt.Fprintf(tw, "\n")
 	}
 }
+
+// The builtin templates for [UsageTemplate]. UsageTable is equivalent to the
+// default [Usage] implementation.
+var (
+	// UsageTable renders the variables as an aligned table.
+	UsageTable = template.Must(template.New("table").Parse(`Usage:
+{{range .}}	{{.Name}}	{{.Type}}	{{if .Required}}required{{else}}default {{if and (not .Default) (eq .Type.Kind.String "string")}}<empty>{{else}}{{.Default}}{{end}}{{end}}{{with .Desc}}	{{.}}{{end}}
+{{end}}`))
+
+	// UsagePlain renders the variables in the style of the flag package.
+	UsagePlain = template.Must(template.New("plain").Parse(`Usage:
+{{range .}}  {{.Name}} {{.Type}}
+    {{with .Desc}}{{.}} {{end}}({{if .Required}}required{{else}}default {{printf "%q" .Default}}{{end}})
+{{end}}`))
+
+	// UsageCompact renders the variables in a single line, marking the
+	// required ones with an asterisk.
+	UsageCompact = template.Must(template.New("compact").Parse(`Usage:
+{{- range .}} {{.Name}}{{if .Required}}*{{else if .Default}}={{.Default}}{{end}}{{end}}
+`))
+)
+
+// UsageTemplate returns a [Usage] implementation that renders the usage message
+// using the provided template, which is executed with a slice of [Var] as the
+// data. Tab-separated columns are aligned using [tabwriter]. It allows to match
+// the existing help-output conventions:
+//
+//	env.Usage = env.UsageTemplate(env.UsageCompact)
+//
+// If the template cannot be executed, the error is written to w instead.
+func UsageTemplate(tmpl *template.Template) func(w io.Writer, vars []Var) {
+	return func(w io.Writer, vars []Var) {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		defer tw.Flush()
+
+		if err := tmpl.Execute(tw, vars); err != nil {
+			fmt.Fprintf(tw, "env: executing usage template: %v\n", err)
+		}
+	}
+}
+
+// UsageTerminal is a [Usage] implementation that detects whether w is a
+// terminal. If so, the usage message is rendered by [UsageWrapped], wrapped to
+// the terminal width and colored, unless the NO_COLOR environment variable is
+// set or TERM is "dumb". The width is queried from the terminal on Linux and
+// macOS; elsewhere, or if the query fails, it is read from the COLUMNS
+// environment variable (80 by default). Otherwise, e.g. when the output is
+// piped into a file, the default plain output is used:
+//
+//	env.Usage = env.UsageTerminal
+func UsageTerminal(w io.Writer, vars []Var) {
+	f, ok := w.(*os.File)
+	if !ok {
+		defaultUsage(w, vars)
+		return
+	}
+	if fi, err := f.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		defaultUsage(w, vars)
+		return
+	}
+
+	width := terminalWidth(f)
+	if width <= 0 {
+		if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+			width = n
+		} else {
+			width = 80
+		}
+	}
+	color := os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+
+	UsageWrapped(width, color)(w, vars)
+}
+
+// UsageWrapped returns a [Usage] implementation that renders the variables as
+// an aligned table, the same way the default one does, but wraps the
+// descriptions to fit into width columns. If color is true, the names of the
+// variables are rendered in bold and the required ones are highlighted in red
+// using ANSI escape sequences. See also [UsageTerminal].
+func UsageWrapped(width int, color bool) func(w io.Writer, vars []Var) {
+	const (
+		bold  = "\x1b[1m"
+		red   = "\x1b[1;31m"
+		reset = "\x1b[0m"
+	)
+
+	return func(w io.Writer, vars []Var) {
+		infos := make([]string, len(vars))
+		var nameWidth, typeWidth, infoWidth int
+		for i, v := range vars {
+			if v.Required {
+				infos[i] = "required"
+			} else {
+				def := v.Default
+				if v.Type.Kind() == reflect.String && def == "" {
+					def = "<empty>"
+				}
+				infos[i] = "default " + def
+			}
+			if len(v.Name) > nameWidth {
+				nameWidth = len(v.Name)
+			}
+			if len(v.Type.String()) > typeWidth {
+				typeWidth = len(v.Type.String())
+			}
+			if len(infos[i]) > infoWidth {
+				infoWidth = len(infos[i])
+			}
+		}
+
+		// the column of the descriptions; if there is not enough space left, the
+		// descriptions are moved to the next line.
+		indent := 2 + nameWidth + 2 + typeWidth + 2 + infoWidth + 2
+		newline := false
+		if width-indent < 20 {
+			indent, newline = 4, true
+		}
+
+		var sb strings.Builder
+		sb.WriteString("Usage:\n")
+		for i, v := range vars {
+			name, info := v.Name, infos[i]
+			if color {
+				name = bold + name + reset
+				if v.Required {
+					info = red + info + reset
+				}
+			}
+
+			row := fmt.Sprintf("  %s%s  %-*s  %s%s",
+				name, strings.Repeat(" ", nameWidth-len(v.Name)),
+				typeWidth, v.Type,
+				info, strings.Repeat(" ", infoWidth-len(infos[i])))
+
+			lines := wrapText(v.Desc, width-indent)
+			if len(lines) == 0 {
+				sb.WriteString(strings.TrimRight(row, " ") + "\n")
+				continue
+			}
+
+			if newline {
+				sb.WriteString(strings.TrimRight(row, " ") + "\n")
+			} else {
+				sb.WriteString(row + "  " + lines[0] + "\n")
+				lines = lines[1:]
+			}
+			for _, line := range lines {
+				sb.WriteString(strings.Repeat(" ", indent) + line + "\n")
+			}
+		}
+
+		fmt.Fprint(w, sb.String())
+	}
+}
+
+// wrapText splits s into lines of at most width characters, breaking at spaces.
+// The words longer than width are not broken.
+func wrapText(s string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
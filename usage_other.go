@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package env
+
+import "os"
+
+// terminalWidth returns 0, since querying the width of the terminal is only
+// supported on Linux and macOS.
+func terminalWidth(*os.File) int { return 0 }
@@ -3,9 +3,14 @@ package env_test
 import (
 	"bytes"
 	"reflect"
+	"strconv"
 	"testing"
+	"text/template"
+	"time"
 
 	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
 )
 
 func TestUsage(t *testing.T) {
@@ -29,3 +34,234 @@ func TestUsage(t *testing.T) {
 		t.Error("usage output mismatch")
 	}
 }
+
+func TestDescribe(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		_, err := env.Describe(nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		var cfg struct {
+			DB struct {
+				Host string `env:"DB_HOST,required" desc:"database host"`
+				Port int    `env:"DB_PORT" default:"5432"`
+			}
+			Token string `env:"TOKEN,secret"`
+		}
+		vars, err := env.Describe(&cfg, env.WithPrefix("APP_"))
+		assert.NoErr[F](t, err)
+		assert.Len[F](t, vars, 3)
+
+		test := func(v env.Var, name, field string, required bool, def string) {
+			t.Run(name, func(t *testing.T) {
+				assert.Equal[E](t, v.Name, name)
+				assert.Equal[E](t, v.Field, field)
+				assert.Equal[E](t, v.Required, required)
+				assert.Equal[E](t, v.Default, def)
+			})
+		}
+
+		test(vars[0], "APP_DB_HOST", "DB.Host", true, "")
+		test(vars[1], "APP_DB_PORT", "DB.Port", false, "5432")
+		test(vars[2], "APP_TOKEN", "Token", false, "")
+		assert.Equal[E](t, vars[0].Desc, "database host")
+		assert.Equal[E](t, vars[2].Secret, true)
+	})
+}
+
+// stringerLevel is a named int with a String method, which must be respected
+// when formatting the default values.
+type stringerLevel int
+
+func (l stringerLevel) String() string { return "level" + strconv.Itoa(int(l)) }
+
+func TestDescribeFieldDefaults(t *testing.T) {
+	cfg := struct {
+		Host    string        `env:"HOST"`
+		Port    uint16        `env:"PORT"`
+		Debug   bool          `env:"DEBUG"`
+		Ratio   float64       `env:"RATIO"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Level   stringerLevel `env:"LEVEL"`
+		Tags    []string      `env:"TAGS"`
+	}{"localhost", 8080, true, 0.5, 5 * time.Second, 2, []string{"a", "b"}}
+
+	vars, err := env.Describe(&cfg)
+	assert.NoErr[F](t, err)
+
+	defaults := make([]string, len(vars))
+	for i, v := range vars {
+		defaults[i] = v.Default
+	}
+	assert.Equal[E](t, defaults, []string{"localhost", "8080", "true", "0.5", "5s", "level2", "[a b]"})
+}
+
+func TestPrintUsage(t *testing.T) {
+	const usage = `Usage:
+  APP_DB_HOST  string  required      database host
+  APP_DB_PORT  int     default 5432  database port
+`
+	var cfg struct {
+		DB struct {
+			Host string `env:"DB_HOST,required" desc:"database host"`
+			Port int    `env:"DB_PORT" default:"5432" desc:"database port"`
+		}
+	}
+
+	var buf bytes.Buffer
+	err := env.PrintUsage(&buf, &cfg, env.WithPrefix("APP_"))
+	assert.NoErr[F](t, err)
+	assert.Equal[E](t, buf.String(), usage)
+
+	err = env.PrintUsage(&buf, nil)
+	assert.IsErr[E](t, err, env.ErrInvalidArgument)
+}
+
+func TestPrintUsageOrder(t *testing.T) {
+	var cfg struct {
+		Port  int    `env:"PORT" default:"8080" group:"http"`
+		Host  string `env:"HOST,required" group:"http"`
+		Debug bool   `env:"DEBUG"`
+		Token string `env:"TOKEN,required"`
+	}
+
+	test := func(name, usage string, opts ...env.Option) {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := env.PrintUsage(&buf, &cfg, opts...)
+			assert.NoErr[F](t, err)
+			assert.Equal[E](t, buf.String(), usage)
+		})
+	}
+
+	test("declaration", `Usage:
+  PORT   int     default 8080
+  HOST   string  required
+  DEBUG  bool    default false
+  TOKEN  string  required
+`)
+
+	test("alphabetical", `Usage:
+  DEBUG  bool    default false
+  HOST   string  required
+  PORT   int     default 8080
+  TOKEN  string  required
+`, env.WithUsageOrder(env.OrderAlphabetical))
+
+	test("group", `Usage:
+  DEBUG  bool    default false
+  TOKEN  string  required
+  PORT   int     default 8080
+  HOST   string  required
+`, env.WithUsageOrder(env.OrderGroup))
+
+	test("required only", `Usage:
+  HOST   string  required
+  TOKEN  string  required
+`, env.WithUsageRequiredOnly())
+
+	t.Run("on error", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := env.LoadFrom(env.Map{}, &cfg, env.WithUsageOnError(&buf), env.WithUsageRequiredOnly())
+		assert.AsErr[E](t, err, new(*env.NotSetError))
+		assert.Equal[E](t, buf.String(), "Usage:\n  HOST   string  required\n  TOKEN  string  required\n")
+	})
+}
+
+func TestUsageTemplate(t *testing.T) {
+	vars := []env.Var{
+		{Name: "DB_HOST", Type: reflect.TypeOf(""), Desc: "database host", Default: ""},
+		{Name: "DB_PORT", Type: reflect.TypeOf(0), Desc: "database port", Required: true},
+		{Name: "HTTP_PORT", Type: reflect.TypeOf(0), Desc: "http server port", Default: "8080"},
+	}
+
+	test := func(name string, tmpl *template.Template, want string) {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			env.UsageTemplate(tmpl)(&buf, vars)
+			assert.Equal[E](t, buf.String(), want)
+		})
+	}
+
+	test("table", env.UsageTable, `Usage:
+  DB_HOST    string  default <empty>  database host
+  DB_PORT    int     required         database port
+  HTTP_PORT  int     default 8080     http server port
+`)
+	test("plain", env.UsagePlain, `Usage:
+  DB_HOST string
+    database host (default "")
+  DB_PORT int
+    database port (required)
+  HTTP_PORT int
+    http server port (default "8080")
+`)
+	test("compact", env.UsageCompact, "Usage: DB_HOST DB_PORT* HTTP_PORT=8080\n")
+	test("error", template.Must(template.New("").Parse("{{.Foo}}")),
+		"env: executing usage template: template: :1:2: executing \"\" at <.Foo>: can't evaluate field Foo in type []env.Var\n")
+}
+
+func TestUsageTableEquivalence(t *testing.T) {
+	vars := []env.Var{
+		{Name: "DB_HOST", Type: reflect.TypeOf(""), Desc: "database host"},
+		{Name: "DB_PORT", Type: reflect.TypeOf(0), Desc: "database port", Required: true},
+		{Name: "TIMEOUT", Type: reflect.TypeOf(time.Duration(0)), Desc: "request timeout"},
+		{Name: "DEBUG", Type: reflect.TypeOf(false), Default: "false"},
+	}
+
+	var want, got bytes.Buffer
+	env.Usage(&want, vars)
+	env.UsageTemplate(env.UsageTable)(&got, vars)
+	assert.Equal[E](t, got.String(), want.String())
+}
+
+func TestUsageWrapped(t *testing.T) {
+	vars := []env.Var{
+		{Name: "DB_HOST", Type: reflect.TypeOf(""), Desc: "the host of the primary database server"},
+		{Name: "DB_PORT", Type: reflect.TypeOf(0), Desc: "database port", Required: true},
+		{Name: "DEBUG", Type: reflect.TypeOf(false), Default: "false"},
+	}
+
+	test := func(name string, width int, color bool, usage string) {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			env.UsageWrapped(width, color)(&buf, vars)
+			assert.Equal[E](t, buf.String(), usage)
+		})
+	}
+
+	test("wide", 80, false, `Usage:
+  DB_HOST  string  default <empty>  the host of the primary database server
+  DB_PORT  int     required         database port
+  DEBUG    bool    default false
+`)
+
+	test("wrapped", 60, false, `Usage:
+  DB_HOST  string  default <empty>  the host of the primary
+                                    database server
+  DB_PORT  int     required         database port
+  DEBUG    bool    default false
+`)
+
+	test("narrow", 40, false, `Usage:
+  DB_HOST  string  default <empty>
+    the host of the primary database
+    server
+  DB_PORT  int     required
+    database port
+  DEBUG    bool    default false
+`)
+
+	test("color", 80, true, "Usage:\n"+
+		"  \x1b[1mDB_HOST\x1b[0m  string  default <empty>  the host of the primary database server\n"+
+		"  \x1b[1mDB_PORT\x1b[0m  int     \x1b[1;31mrequired\x1b[0m         database port\n"+
+		"  \x1b[1mDEBUG\x1b[0m    bool    default false\n")
+}
+
+func TestUsageTerminal(t *testing.T) {
+	// not a terminal: the default output is used.
+	var buf bytes.Buffer
+	env.UsageTerminal(&buf, []env.Var{{Name: "PORT", Type: reflect.TypeOf(0), Required: true}})
+	assert.Equal[E](t, buf.String(), "Usage:\n  PORT  int  required\n")
+}
@@ -0,0 +1,58 @@
+package env
+
+import (
+	"strings"
+)
+
+// Viper is a tiny subset of the [github.com/spf13/viper] API. *viper.Viper
+// implements it, which allows mixed codebases to use both packages during a
+// migration without making this package depend on viper.
+type Viper interface {
+	SetDefault(key string, value any)
+	BindEnv(input ...string) error
+	IsSet(key string) bool
+	GetString(key string) string
+}
+
+// ConfigureViper populates the provided [Viper] instance with the metadata of
+// the environment variables parsed from the fields of dst: each variable is
+// bound to the viper key derived from its name by converting it to lowercase,
+// e.g. DB_HOST becomes db_host, and its default value (if any) is set as the
+// key's default. The defaults are formatted so that they can be loaded back
+// using [ViperProvider], e.g. slices are joined using the separator configured
+// by [WithSliceSeparator]. See [Describe] for how dst is parsed.
+func ConfigureViper(v Viper, dst any, opts ...Option) error {
+	vars, err := describeVars(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range vars {
+		key := strings.ToLower(ev.Name)
+		if err := v.BindEnv(key, ev.Name); err != nil {
+			return err
+		}
+		if !ev.Required {
+			v.SetDefault(key, ev.Default)
+		}
+	}
+
+	return nil
+}
+
+// ViperProvider returns a [Provider] that looks up environment variables in
+// the provided [Viper] instance, using the keys derived the same way
+// [ConfigureViper] does it. It allows loading a struct from viper:
+//
+//	if err := env.LoadFrom(env.ViperProvider(v), &cfg); err != nil {
+//		// handle error
+//	}
+func ViperProvider(v Viper) Provider {
+	return ProviderFunc(func(key string) (string, bool) {
+		key = strings.ToLower(key)
+		if !v.IsSet(key) {
+			return "", false
+		}
+		return v.GetString(key), true
+	})
+}
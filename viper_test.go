@@ -0,0 +1,104 @@
+package env_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/junk1tm/env"
+	"github.com/junk1tm/env/assert"
+	. "github.com/junk1tm/env/assert/dotimport"
+)
+
+// fakeViper is a [env.Viper] implementation mimicking viper's behaviour.
+type fakeViper struct {
+	env      env.Map
+	bindings map[string]string
+	defaults map[string]any
+}
+
+func newFakeViper(m env.Map) *fakeViper {
+	return &fakeViper{env: m, bindings: make(map[string]string), defaults: make(map[string]any)}
+}
+
+func (v *fakeViper) SetDefault(key string, value any) { v.defaults[strings.ToLower(key)] = value }
+
+func (v *fakeViper) BindEnv(input ...string) error {
+	v.bindings[input[0]] = input[1]
+	return nil
+}
+
+func (v *fakeViper) IsSet(key string) bool {
+	_, ok := v.get(key)
+	return ok
+}
+
+func (v *fakeViper) GetString(key string) string {
+	value, _ := v.get(key)
+	return fmt.Sprint(value)
+}
+
+func (v *fakeViper) get(key string) (any, bool) {
+	if value, ok := v.env[v.bindings[key]]; ok {
+		return value, true
+	}
+	value, ok := v.defaults[key]
+	return value, ok
+}
+
+func TestViper(t *testing.T) {
+	var cfg struct {
+		Host string `env:"DB_HOST,required"`
+		Port int    `env:"DB_PORT" default:"5432"`
+	}
+
+	t.Run("invalid argument", func(t *testing.T) {
+		err := env.ConfigureViper(newFakeViper(nil), nil)
+		assert.IsErr[E](t, err, env.ErrInvalidArgument)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		v := newFakeViper(env.Map{"DB_HOST": "localhost"})
+		err := env.ConfigureViper(v, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, v.bindings, map[string]string{"db_host": "DB_HOST", "db_port": "DB_PORT"})
+		assert.Equal[E](t, v.defaults, map[string]any{"db_port": "5432"})
+
+		err = env.LoadFrom(env.ViperProvider(v), &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Host, "localhost")
+		assert.Equal[E](t, cfg.Port, 5432)
+	})
+
+	t.Run("slice defaults", func(t *testing.T) {
+		cfg := struct {
+			Ports []int    `env:"PORTS"`
+			Hosts []string `env:"HOSTS" default:"a.example.com;b.example.com"`
+		}{
+			Ports: []int{80, 443},
+		}
+
+		v := newFakeViper(env.Map{})
+		err := env.ConfigureViper(v, &cfg, env.WithSliceSeparator(";"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, v.defaults, map[string]any{"ports": "80;443", "hosts": "a.example.com;b.example.com"})
+
+		cfg.Ports, cfg.Hosts = nil, nil
+		err = env.LoadFrom(env.ViperProvider(v), &cfg, env.WithSliceSeparator(";"))
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, cfg.Ports, []int{80, 443})
+		assert.Equal[E](t, cfg.Hosts, []string{"a.example.com", "b.example.com"})
+	})
+
+	t.Run("template defaults", func(t *testing.T) {
+		var cfg struct {
+			Host string `env:"HOST" default:"localhost"`
+			URL  string `env:"URL,template" default:"http://{{.Host}}"`
+		}
+
+		v := newFakeViper(env.Map{})
+		err := env.ConfigureViper(v, &cfg)
+		assert.NoErr[F](t, err)
+		assert.Equal[E](t, v.defaults, map[string]any{"host": "localhost", "url": "http://localhost"})
+	})
+}